@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"ai_rename/internal/rename"
+)
+
+// runApply implements the apply subcommand: rename the selected identifier
+// to a given name across the loaded package, without asking an LLM.
+func runApply(args []string) int {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	module := fs.Bool("module", false, "also rename references in every package of the enclosing module")
+	dryRun := fs.Bool("dry-run", false, "print the edits instead of writing them")
+	diff := fs.Bool("diff", false, "print a unified diff instead of writing the files")
+	fs.Parse(args)
+
+	if fs.NArg() != 3 {
+		fmt.Fprintln(os.Stderr, "usage: ai_rename_bin apply [-module] [-dry-run | -diff] <file.go> <row:col> <new-name>")
+		return 1
+	}
+
+	selector, err := parseSelector(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	result, err := rename.Rename(fs.Arg(0), selector, fs.Arg(2), rename.Options{
+		Load: rename.LoadOptions{Module: *module, Tests: true},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *diff {
+		return printDiff(result, result.Suggestions[0])
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+
+	edits := result.Suggestions[0].Edits
+	if *dryRun {
+		for _, e := range edits {
+			fmt.Printf("%s:%d:%d: %q -> %q\n", e.Filename, e.Line, e.Column, e.OldText, e.NewText)
+		}
+		return 0
+	}
+
+	if err := rename.ApplyEdits(edits); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	files := map[string]bool{}
+	for _, e := range edits {
+		files[e.Filename] = true
+	}
+	fmt.Printf("applied %d edits in %d files\n", len(edits), len(files))
+	return 0
+}
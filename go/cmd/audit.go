@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ai_rename/internal/rename"
+)
+
+type jsonFinding struct {
+	Name        string           `json:"name"`
+	Kind        string           `json:"kind"`
+	Position    string           `json:"position"`
+	Selector    string           `json:"selector"`
+	Score       int              `json:"score"`
+	Problems    []string         `json:"problems"`
+	Suggestions []jsonSuggestion `json:"suggestions,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// runAudit implements the audit subcommand: rank the poorly named
+// identifiers of a package, optionally asking the LLM for better names.
+func runAudit(args []string) int {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	module := fs.Bool("module", false, "load every package of the enclosing module for context")
+	ask := fs.Bool("ask", false, "ask the LLM for suggestions for the flagged names")
+	providerName := fs.String("llm", "ollama", "LLM provider used by -ask: "+strings.Join(rename.ProviderNames(), ", "))
+	limit := fs.Int("limit", 10, "number of worst findings sent to the LLM by -ask, 0 for all")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: ai_rename_bin audit [-module] [-ask [-llm %s] [-limit n]] <file.go | dir>\n", strings.Join(rename.ProviderNames(), "|"))
+		return 1
+	}
+
+	opts := rename.AuditOptions{Load: rename.LoadOptions{Module: *module}, Limit: *limit}
+	if *ask {
+		provider, err := rename.NewProvider(*providerName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		opts.Provider = provider
+	}
+
+	findings, err := rename.Audit(fs.Arg(0), opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out := struct {
+		Findings []jsonFinding `json:"findings"`
+	}{Findings: []jsonFinding{}}
+	for _, f := range findings {
+		jf := jsonFinding{
+			Name:     f.Name,
+			Kind:     f.Kind,
+			Position: f.Position,
+			Selector: fmt.Sprintf("%d:%d", f.Selector.Row, f.Selector.Col),
+			Score:    f.Score,
+			Problems: f.Problems,
+			Error:    f.Err,
+		}
+		for _, s := range f.Suggestions {
+			jf.Suggestions = append(jf.Suggestions, jsonSuggestion{Name: s.Name, Reason: s.Reason})
+		}
+		out.Findings = append(out.Findings, jf)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ai_rename/internal/rename"
+)
+
+// runEval implements the eval subcommand: score a provider's suggestions
+// against a corpus of obfuscated identifiers with known good names.
+func runEval(args []string) int {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	providerName := fs.String("llm", "ollama", "LLM provider: "+strings.Join(rename.ProviderNames(), ", "))
+	module := fs.Bool("module", false, "load every package of the enclosing module for context")
+	out := fs.String("out", "", "write the JSON report to this file instead of stdout")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: ai_rename_bin eval [-llm %s] [-module] [-out report.json] <corpus.json>\n", strings.Join(rename.ProviderNames(), "|"))
+		return 1
+	}
+
+	provider, err := rename.NewProvider(*providerName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	report, err := rename.Evaluate(fs.Arg(0), provider, rename.Options{Load: rename.LoadOptions{Module: *module}})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %d items, %d errors, exact %.1f%%, top-3 %.1f%%, valid %.1f%%, mean latency %dms\n",
+		report.Provider, report.Items, report.Errors, 100*report.ExactMatch, 100*report.Top3HitRate, 100*report.ValidityRate, report.MeanLatencyMS)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -12,59 +12,154 @@ import (
 )
 
 type jsonSuggestion struct {
+	Name    string            `json:"name"`
+	Reason  string            `json:"reason"`
+	Mapping map[string]string `json:"mapping,omitempty"`
+	Edits   []jsonEdit        `json:"edits,omitempty"`
+
+	WorkspaceEdit *rename.WorkspaceEdit `json:"workspaceEdit,omitempty"`
+}
+
+type jsonEdit struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Col    int    `json:"col"`
+	Offset int    `json:"offset"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+type jsonRejection struct {
 	Name   string `json:"name"`
 	Reason string `json:"reason"`
 }
 
 type jsonOutput struct {
 	Suggestions []jsonSuggestion `json:"suggestions"`
+	Rejected    []jsonRejection  `json:"rejected,omitempty"`
+	Warnings    []string         `json:"warnings,omitempty"`
 }
 
 func main() {
-	provider := flag.String("llm", "ollama", "LLM provider: ollama or claude")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "apply":
+			os.Exit(runApply(os.Args[2:]))
+		case "audit":
+			os.Exit(runAudit(os.Args[2:]))
+		case "eval":
+			os.Exit(runEval(os.Args[2:]))
+		}
+	}
+
+	providerName := flag.String("llm", "ollama", "LLM provider: "+strings.Join(rename.ProviderNames(), ", "))
+	module := flag.Bool("module", false, "load every package of the enclosing module for context")
+	group := flag.Bool("group", false, "rename the whole const block (and its type) of the selected constant as one unit")
+	diff := flag.Bool("diff", false, "print a unified diff of the suggestion chosen by -pick instead of JSON")
+	pick := flag.Int("pick", 1, "suggestion previewed by -diff, 1-based")
+	lsp := flag.Bool("lsp", false, "add an LSP WorkspaceEdit applying each suggestion to the JSON output")
 	flag.Parse()
 
 	args := flag.Args()
 	if len(args) != 2 {
-		fmt.Fprintln(os.Stderr, "usage: ai_rename_bin [-llm ollama|claude] <file.go> <row:col>")
-		os.Exit(1)
-	}
-
-	filePath := args[0]
-	parts := strings.SplitN(args[1], ":", 2)
-	if len(parts) != 2 {
-		fmt.Fprintln(os.Stderr, "selector must be <row>:<col>")
+		fmt.Fprintf(os.Stderr, "usage: ai_rename_bin [-llm %s] [-module] [-group] [-diff [-pick n] | -lsp] <file.go> <row:col>\n", strings.Join(rename.ProviderNames(), "|"))
+		fmt.Fprintln(os.Stderr, "       ai_rename_bin apply [-module] [-dry-run | -diff] <file.go> <row:col> <new-name>")
+		fmt.Fprintln(os.Stderr, "       ai_rename_bin audit [-module] [-ask [-llm name] [-limit n]] <file.go | dir>")
+		fmt.Fprintln(os.Stderr, "       ai_rename_bin eval [-llm name] [-module] [-out report.json] <corpus.json>")
 		os.Exit(1)
 	}
 
-	row, err := strconv.Atoi(parts[0])
+	provider, err := rename.NewProvider(*providerName)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "row must be an integer")
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	col, err := strconv.Atoi(parts[1])
+
+	filePath := args[0]
+	selector, err := parseSelector(args[1])
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "col must be an integer")
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	result, err := rename.Run(filePath, rename.Selector{
-		Kind: "position",
-		Row:  row,
-		Col:  col,
-	}, *provider)
+	result, err := rename.Run(filePath, selector, provider, rename.Options{
+		Load:  rename.LoadOptions{Module: *module},
+		Group: *group,
+	})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
+	if *diff {
+		if *pick < 1 || *pick > len(result.Suggestions) {
+			fmt.Fprintf(os.Stderr, "-pick %d: there are %d suggestions\n", *pick, len(result.Suggestions))
+			os.Exit(1)
+		}
+		os.Exit(printDiff(result, result.Suggestions[*pick-1]))
+	}
+
 	var suggs []jsonSuggestion
 	for _, s := range result.Suggestions {
-		suggs = append(suggs, jsonSuggestion{Name: s.Name, Reason: s.Reason})
+		var edits []jsonEdit
+		for _, e := range s.Edits {
+			edits = append(edits, jsonEdit{File: e.Filename, Line: e.Line, Col: e.Column, Offset: e.Offset, Old: e.OldText, New: e.NewText})
+		}
+		sugg := jsonSuggestion{Name: s.Name, Reason: s.Reason, Mapping: s.Mapping, Edits: edits}
+		if *lsp {
+			if sugg.WorkspaceEdit, err = rename.NewWorkspaceEdit(s.Edits); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		suggs = append(suggs, sugg)
 	}
 
-	if err := json.NewEncoder(os.Stdout).Encode(jsonOutput{Suggestions: suggs}); err != nil {
+	var rejected []jsonRejection
+	for _, r := range result.Rejected {
+		rejected = append(rejected, jsonRejection{Name: r.Name, Reason: r.Reason})
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(jsonOutput{Suggestions: suggs, Rejected: rejected, Warnings: result.Warnings}); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// printDiff prints the unified diff of suggestion, and the warnings of
+// result on stderr.
+func printDiff(result *rename.Result, suggestion rename.Suggestion) int {
+	for _, w := range result.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+	d, err := rename.Diff(suggestion.Edits)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(d)
+	return 0
+}
+
+// parseSelector parses a "<row>:<col>" position selector.
+func parseSelector(s string) (rename.Selector, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return rename.Selector{}, fmt.Errorf("selector must be <row>:<col>")
+	}
+
+	row, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return rename.Selector{}, fmt.Errorf("row must be an integer")
+	}
+	col, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return rename.Selector{}, fmt.Errorf("col must be an integer")
+	}
+
+	return rename.Selector{
+		Kind: "position",
+		Row:  row,
+		Col:  col,
+	}, nil
+}
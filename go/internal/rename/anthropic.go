@@ -0,0 +1,185 @@
+package rename
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	RegisterProvider("anthropic", func() (Provider, error) { return NewAnthropicProvider() })
+}
+
+const anthropicVersion = "2023-06-01"
+
+// statusOverloaded is the non-standard status the Messages API returns when
+// it is temporarily overloaded.
+const statusOverloaded = 529
+
+// AnthropicProvider calls the Anthropic Messages API directly over HTTP, so
+// it works on machines without the Claude Code CLI.
+type AnthropicProvider struct {
+	BaseURL    string // e.g. https://api.anthropic.com
+	APIKey     string
+	Model      string
+	MaxTokens  int
+	MaxRetries int           // retries on 429 and 529 responses
+	Backoff    time.Duration // initial delay, doubled after each retry
+	Client     *http.Client
+}
+
+// NewAnthropicProvider builds an AnthropicProvider from the environment:
+//
+//	ANTHROPIC_API_KEY               API key (required)
+//	ANTHROPIC_BASE_URL              API base URL (default https://api.anthropic.com)
+//	AI_RENAME_ANTHROPIC_MODEL       model name (default claude-sonnet-4-5)
+//	AI_RENAME_ANTHROPIC_MAX_TOKENS  response token limit (default 512)
+func NewAnthropicProvider() (*AnthropicProvider, error) {
+	key := os.Getenv("ANTHROPIC_API_KEY")
+	if key == "" {
+		return nil, fmt.Errorf("anthropic: ANTHROPIC_API_KEY is not set")
+	}
+	p := &AnthropicProvider{
+		BaseURL:    normalizeBaseURL(envOr("ANTHROPIC_BASE_URL", "https://api.anthropic.com")),
+		APIKey:     key,
+		Model:      envOr("AI_RENAME_ANTHROPIC_MODEL", "claude-sonnet-4-5"),
+		MaxTokens:  512,
+		MaxRetries: 4,
+		Backoff:    time.Second,
+	}
+	if raw := os.Getenv("AI_RENAME_ANTHROPIC_MAX_TOKENS"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("AI_RENAME_ANTHROPIC_MAX_TOKENS: invalid value %q", raw)
+		}
+		p.MaxTokens = n
+	}
+	return p, nil
+}
+
+func (p *AnthropicProvider) Name() string { return "anthropic" }
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model      string             `json:"model"`
+	MaxTokens  int                `json:"max_tokens"`
+	Messages   []anthropicMessage `json:"messages"`
+	Tools      []anthropicTool    `json:"tools,omitempty"`
+	ToolChoice map[string]string  `json:"tool_choice,omitempty"`
+}
+
+type anthropicContent struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	Input json.RawMessage `json:"input"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContent `json:"content"`
+	Error   *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Generate sends prompt, which carries its own output policy, as the only
+// user turn. Rate-limited (429) and overloaded (529) responses are retried
+// with exponential backoff, honouring Retry-After when the server sends it
+// unless it asks for longer than the backoff would ever wait.
+func (p *AnthropicProvider) Generate(prompt string) (string, error) {
+	req := anthropicRequest{
+		Model:     p.Model,
+		MaxTokens: p.MaxTokens,
+		Messages:  []anthropicMessage{{Role: "user", Content: prompt}},
+	}
+	return p.send(req)
+}
+
+// GenerateJSON forces a call to a single tool whose input schema is schema
+// and returns the tool input as JSON.
+func (p *AnthropicProvider) GenerateJSON(prompt string, schema map[string]any) (string, error) {
+	const tool = "suggest_names"
+	req := anthropicRequest{
+		Model:     p.Model,
+		MaxTokens: p.MaxTokens,
+		Messages:  []anthropicMessage{{Role: "user", Content: prompt}},
+		Tools: []anthropicTool{{
+			Name:        tool,
+			Description: "Report the suggested identifier names.",
+			InputSchema: schema,
+		}},
+		ToolChoice: map[string]string{"type": "tool", "name": tool},
+	}
+	return p.send(req)
+}
+
+// send posts req and returns the text of the reply, or the input of the
+// first tool_use block when the model called a tool.
+func (p *AnthropicProvider) send(req anthropicRequest) (string, error) {
+	header := http.Header{}
+	header.Set("x-api-key", p.APIKey)
+	header.Set("anthropic-version", anthropicVersion)
+
+	delay := p.Backoff
+	maxDelay := p.Backoff << max(p.MaxRetries-1, 0)
+	for attempt := 0; ; attempt++ {
+		reply, err := postJSON(p.Client, p.BaseURL+"/v1/messages", header, req)
+		if err != nil {
+			return "", fmt.Errorf("anthropic: %w", err)
+		}
+
+		var resp anthropicResponse
+		decodeErr := json.Unmarshal(reply.Body, &resp)
+
+		if (reply.Status == http.StatusTooManyRequests || reply.Status == statusOverloaded) && attempt < p.MaxRetries {
+			wait := delay
+			if secs, err := strconv.Atoi(reply.Header.Get("Retry-After")); err == nil && secs > 0 {
+				wait = time.Duration(secs) * time.Second
+				if wait > maxDelay {
+					return "", fmt.Errorf("anthropic: HTTP %d, server asks to retry after %s", reply.Status, wait)
+				}
+			}
+			log.Printf("[llm] anthropic: HTTP %d, retrying in %s", reply.Status, wait)
+			time.Sleep(wait)
+			delay *= 2
+			continue
+		}
+
+		if reply.Status != http.StatusOK {
+			var msg string
+			if resp.Error != nil {
+				msg = resp.Error.Message
+			}
+			return "", statusError("anthropic", reply.Status, msg, reply.Body)
+		}
+		if decodeErr != nil {
+			return "", fmt.Errorf("anthropic: decoding response: %w", decodeErr)
+		}
+
+		var text strings.Builder
+		for _, c := range resp.Content {
+			switch c.Type {
+			case "tool_use":
+				return string(c.Input), nil
+			case "text":
+				text.WriteString(c.Text)
+			}
+		}
+		return text.String(), nil
+	}
+}
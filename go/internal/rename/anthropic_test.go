@@ -0,0 +1,204 @@
+package rename
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeAnthropic starts a local stand-in for the Messages API. handle is
+// called once per request with the 1-based call number.
+func fakeAnthropic(t *testing.T, handle func(call int, r *http.Request, req anthropicRequest) (int, any)) *httptest.Server {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		status, body := handle(int(calls.Add(1)), r, req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func anthropicText(text string) map[string]any {
+	return map[string]any{
+		"type":    "message",
+		"role":    "assistant",
+		"content": []map[string]string{{"type": "text", "text": text}},
+	}
+}
+
+func anthropicError(typ, msg string) map[string]any {
+	return map[string]any{
+		"type":  "error",
+		"error": map[string]string{"type": typ, "message": msg},
+	}
+}
+
+func testAnthropicProvider(url string) *AnthropicProvider {
+	return &AnthropicProvider{
+		BaseURL:    url,
+		APIKey:     "test-key",
+		Model:      "claude-test",
+		MaxTokens:  128,
+		MaxRetries: 3,
+		Backoff:    time.Millisecond,
+	}
+}
+
+func TestAnthropicProviderGenerate(t *testing.T) {
+	srv := fakeAnthropic(t, func(call int, r *http.Request, req anthropicRequest) (int, any) {
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key = %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != anthropicVersion {
+			t.Errorf("anthropic-version = %q", got)
+		}
+		if req.Model != "claude-test" || req.MaxTokens != 128 {
+			t.Errorf("request = %+v", req)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" || req.Messages[0].Content != "rename amt" {
+			t.Errorf("messages = %+v", req.Messages)
+		}
+		return http.StatusOK, anthropicText("discount - amount off")
+	})
+
+	out, err := testAnthropicProvider(srv.URL).Generate("rename amt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "discount - amount off" {
+		t.Errorf("Generate() = %q", out)
+	}
+}
+
+func TestAnthropicProviderRetries(t *testing.T) {
+	srv := fakeAnthropic(t, func(call int, r *http.Request, req anthropicRequest) (int, any) {
+		switch call {
+		case 1:
+			return http.StatusTooManyRequests, anthropicError("rate_limit_error", "slow down")
+		case 2:
+			return statusOverloaded, anthropicError("overloaded_error", "Overloaded")
+		default:
+			return http.StatusOK, anthropicText("total - order sum")
+		}
+	})
+
+	out, err := testAnthropicProvider(srv.URL).Generate("rename amt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "total - order sum" {
+		t.Errorf("Generate() = %q", out)
+	}
+}
+
+func TestAnthropicProviderGivesUp(t *testing.T) {
+	var calls int
+	srv := fakeAnthropic(t, func(call int, r *http.Request, req anthropicRequest) (int, any) {
+		calls = call
+		return statusOverloaded, anthropicError("overloaded_error", "Overloaded")
+	})
+
+	_, err := testAnthropicProvider(srv.URL).Generate("rename amt")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 529: Overloaded") {
+		t.Fatalf("error = %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("server saw %d calls, want 4", calls)
+	}
+}
+
+func TestAnthropicProviderLongRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(anthropicError("rate_limit_error", "Rate limited"))
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := testAnthropicProvider(srv.URL).Generate("rename amt")
+	if err == nil || !strings.Contains(err.Error(), "retry after 1h0m0s") {
+		t.Fatalf("error = %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server saw %d calls, want 1", n)
+	}
+}
+
+func TestAnthropicProviderError(t *testing.T) {
+	srv := fakeAnthropic(t, func(call int, r *http.Request, req anthropicRequest) (int, any) {
+		return http.StatusUnauthorized, anthropicError("authentication_error", "invalid x-api-key")
+	})
+
+	_, err := testAnthropicProvider(srv.URL).Generate("rename amt")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401: invalid x-api-key") {
+		t.Fatalf("error = %v", err)
+	}
+}
+
+func TestNewAnthropicProviderFromEnv(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	if _, err := NewAnthropicProvider(); err == nil {
+		t.Error("expected error without ANTHROPIC_API_KEY")
+	}
+
+	t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+	t.Setenv("ANTHROPIC_BASE_URL", "http://localhost:8080/")
+	t.Setenv("AI_RENAME_ANTHROPIC_MODEL", "claude-other")
+	t.Setenv("AI_RENAME_ANTHROPIC_MAX_TOKENS", "64")
+	p, err := NewAnthropicProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.BaseURL != "http://localhost:8080" || p.Model != "claude-other" || p.MaxTokens != 64 {
+		t.Errorf("provider = %+v", p)
+	}
+
+	t.Setenv("AI_RENAME_ANTHROPIC_MAX_TOKENS", "lots")
+	if _, err := NewAnthropicProvider(); err == nil {
+		t.Error("expected error for invalid max tokens")
+	}
+}
+
+func TestAnthropicProviderGenerateJSON(t *testing.T) {
+	srv := fakeAnthropic(t, func(call int, r *http.Request, req anthropicRequest) (int, any) {
+		if len(req.Tools) != 1 || req.ToolChoice["name"] != req.Tools[0].Name {
+			t.Errorf("tools = %+v, tool_choice = %v", req.Tools, req.ToolChoice)
+		}
+		return http.StatusOK, map[string]any{
+			"type": "message",
+			"role": "assistant",
+			"content": []map[string]any{{
+				"type":  "tool_use",
+				"id":    "toolu_1",
+				"name":  req.Tools[0].Name,
+				"input": map[string]any{"suggestions": []map[string]string{{"name": "total", "reason": "order sum"}}},
+			}},
+		}
+	})
+
+	suggestions, err := CallLLM("rename amt", testAnthropicProvider(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Name != "total" || suggestions[0].Reason != "order sum" {
+		t.Errorf("suggestions = %+v", suggestions)
+	}
+}
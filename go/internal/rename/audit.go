@@ -0,0 +1,263 @@
+package rename
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Finding is an identifier Audit considers poorly named.
+type Finding struct {
+	Name     string
+	Kind     string // e.g. "package-level type", "parameter"
+	Position string // file:line:col
+	Selector Selector
+	Score    int      // higher is worse
+	Problems []string // why the name was flagged
+
+	// Suggestions and Err are filled in for findings sent to the LLM.
+	Suggestions []Suggestion
+	Err         string
+}
+
+// AuditOptions tunes an Audit.
+type AuditOptions struct {
+	Load LoadOptions
+
+	// Provider, if set, is asked for suggestions for the Limit
+	// worst-scoring findings (all of them when Limit is 0).
+	Provider Provider
+	Limit    int
+}
+
+// vagueNames carry no meaning on their own.
+var vagueNames = []string{"data", "tmp", "temp", "val", "value", "obj", "object", "thing", "stuff", "info", "item", "foo", "bar", "baz", "res", "ret"}
+
+// vagueSuffixes add nothing to the name of a type or variable they end,
+// e.g. OrderStruct or userData.
+var vagueSuffixes = []string{"Struct", "Data", "Tmp", "Temp", "Obj", "Object"}
+
+// typeSuffixes repeat the type of a variable in its name, e.g. nameStr.
+var typeSuffixes = []string{"Str", "Int", "Map", "Slice", "Arr"}
+
+// Audit scores the name of every identifier declared in the package
+// containing path, which may be a Go file or its directory, and returns
+// those with problems, worst first.
+func Audit(path string, opts AuditOptions) ([]Finding, error) {
+	filename, err := packageFile(path)
+	if err != nil {
+		return nil, err
+	}
+	src, err := Load(filename, opts.Load)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	var idents []*ast.Ident // parallel to findings
+	for _, f := range src.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := src.Info.Defs[id]
+			if obj == nil || obj.Pkg() != src.Pkg || obj.Name() == "_" {
+				return true
+			}
+			score, problems := src.scoreName(obj)
+			if score == 0 {
+				return true
+			}
+			p := src.Fset.Position(id.Pos())
+			findings = append(findings, Finding{
+				Name:     id.Name,
+				Kind:     src.auditKind(obj),
+				Position: src.position(id.Pos()),
+				Selector: Selector{Kind: "position", Row: p.Line, Col: p.Column - 1},
+				Score:    score,
+				Problems: problems,
+			})
+			idents = append(idents, id)
+			return true
+		})
+	}
+
+	order := make([]int, len(findings))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(findings[b].Score, findings[a].Score) })
+
+	ranked := make([]Finding, len(findings))
+	for i, j := range order {
+		ranked[i] = findings[j]
+		if opts.Provider == nil || (opts.Limit > 0 && i >= opts.Limit) {
+			continue
+		}
+		result, err := suggestFor(src, idents[j], opts.Provider)
+		if err != nil {
+			ranked[i].Err = err.Error()
+			continue
+		}
+		ranked[i].Suggestions = result.Suggestions
+	}
+	return ranked, nil
+}
+
+// scoreName applies the naming heuristics to obj, returning a score (0 for
+// a fine name) and the problems found.
+func (s *Source) scoreName(obj types.Object) (int, []string) {
+	name := obj.Name()
+	score := 0
+	var problems []string
+	flag := func(points int, format string, args ...any) {
+		score += points
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if fn, ok := obj.(*types.Func); ok && (name == "init" || name == "main") && fn.Type().(*types.Signature).Recv() == nil {
+		return 0, nil
+	}
+
+	kind := ""
+	if v, ok := obj.(*types.Var); ok && !v.IsField() {
+		kind, _ = s.varKind(v)
+	}
+
+	// receivers and type parameters are conventionally short
+	if kind == "receiver" {
+		if name == "this" || name == "self" {
+			flag(2, "receiver named %q; Go receivers abbreviate the type", name)
+		}
+		return score, problems
+	}
+	if _, ok := obj.Type().(*types.TypeParam); ok {
+		return 0, nil
+	}
+
+	packageLevel := obj.Parent() == s.Pkg.Scope()
+	_, isFunc := obj.(*types.Func)
+	if len(name) == 1 {
+		switch {
+		case packageLevel:
+			flag(3, "single-letter name at package level")
+		case obj.Parent() == nil: // fields and methods
+			flag(2, "single-letter %s name", s.describe(obj))
+		case kind != "range key" && kind != "range value" && !s.isLoopVar(obj):
+			if span := s.span(obj); span > 15 {
+				flag(2, "single-letter name used over %d lines", span)
+			}
+		}
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case slices.Contains(vagueNames, lower):
+		flag(2, "%q says nothing about what it holds", name)
+	case isFunc:
+	default:
+		suffixes := vagueSuffixes
+		if _, ok := obj.(*types.Var); ok {
+			suffixes = append(slices.Clip(suffixes), typeSuffixes...)
+		}
+		for _, suffix := range suffixes {
+			if len(name) > len(suffix) && strings.HasSuffix(name, suffix) {
+				flag(2, "suffix %q adds no meaning", suffix)
+				break
+			}
+		}
+	}
+
+	if strings.Contains(strings.Trim(name, "_"), "_") {
+		flag(2, "underscores; Go uses MixedCaps")
+	}
+
+	pkg := s.Pkg.Name()
+	if packageLevel && obj.Exported() && pkg != "main" && hasWordPrefix(lower[:1]+name[1:], pkg) {
+		flag(2, "stutters: %s.%s", pkg, name)
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			t := recv.Type()
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			if named, ok := t.(*types.Named); ok && hasWordPrefix(name, named.Obj().Name()) {
+				flag(1, "repeats the receiver type: %s.%s", named.Obj().Name(), name)
+			}
+		}
+	}
+
+	if len(name) > 30 {
+		flag(1, "%d characters long", len(name))
+	}
+	return score, problems
+}
+
+// hasWordPrefix reports whether name starts with prefix followed by
+// another MixedCaps word, as Order does in OrderStruct but not in Orders.
+func hasWordPrefix(name, prefix string) bool {
+	return len(name) > len(prefix) && strings.HasPrefix(name, prefix) && unicode.IsUpper(rune(name[len(prefix)]))
+}
+
+// isLoopVar reports whether obj is declared in the init statement of a
+// for loop, where i, j and k are idiomatic.
+func (s *Source) isLoopVar(obj types.Object) bool {
+	path := s.pathTo(obj.Pos())
+	for i, n := range path {
+		if _, ok := n.(*ast.AssignStmt); ok && i+1 < len(path) {
+			loop, ok := path[i+1].(*ast.ForStmt)
+			return ok && loop.Init == n
+		}
+	}
+	return false
+}
+
+// span returns the number of lines between the declaration of obj and its
+// last use in the loaded package.
+func (s *Source) span(obj types.Object) int {
+	first := s.Fset.Position(obj.Pos()).Line
+	last := first
+	for _, id := range s.refs(obj, s.fileOf(obj.Pos())) {
+		last = max(last, s.Fset.Position(id.Pos()).Line)
+	}
+	return last - first
+}
+
+// auditKind describes obj for the report, with variables classified by
+// varKind.
+func (s *Source) auditKind(obj types.Object) string {
+	if v, ok := obj.(*types.Var); ok && !v.IsField() && v.Parent() != s.Pkg.Scope() {
+		kind, _ := s.varKind(v)
+		return kind
+	}
+	return s.describe(obj)
+}
+
+// packageFile returns path if it is a file, or the first non-test Go file
+// of the directory path.
+func packageFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	fset := token.NewFileSet()
+	files, err := parseDir(fset, path, "", "", false)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no Go files in %s", path)
+	}
+	return fset.Position(files[0].Pos()).Filename, nil
+}
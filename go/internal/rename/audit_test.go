@@ -0,0 +1,90 @@
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const auditSrc = `package order
+
+type OrderStruct struct {
+	ID int
+	x  string
+}
+
+func (this *OrderStruct) OrderStructTotal() int { return this.ID }
+
+func (o *OrderStruct) Sum(items []int) int {
+	total := 0
+	for i := 0; i < len(items); i++ {
+		total += items[i]
+	}
+	for _, v := range items {
+		total += v
+	}
+	return total
+}
+
+var T = 1
+
+func parse(raw string) (user_name string) {
+	data := raw
+	return data
+}
+`
+
+func TestAudit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "order.go"), []byte(auditSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := Audit(dir, AuditOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Name+": "+strings.Join(f.Problems, "; "))
+	}
+	want := []string{
+		`OrderStruct: suffix "Struct" adds no meaning; stutters: order.OrderStruct`,
+		`T: single-letter name at package level`,
+		`x: single-letter field name`,
+		`this: receiver named "this"; Go receivers abbreviate the type`,
+		`user_name: underscores; Go uses MixedCaps`,
+		`data: "data" says nothing about what it holds`,
+		`OrderStructTotal: repeats the receiver type: OrderStruct.OrderStructTotal`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findings:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if f := findings[0]; f.Score != 4 || f.Kind != "package-level type" || f.Selector.Row != 3 || f.Selector.Col != 5 {
+		t.Errorf("findings[0] = %+v", f)
+	}
+}
+
+func TestAuditAsksForFlaggedNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.go")
+	if err := os.WriteFile(path, []byte(auditSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{replies: []string{`[{"name":"Order","reason":"the package already says order"}]`}}
+
+	findings, err := Audit(path, AuditOptions{Provider: p, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.prompts) != 1 {
+		t.Fatalf("%d prompts sent, want 1", len(p.prompts))
+	}
+	if s := findings[0].Suggestions; len(s) != 1 || s[0].Name != "Order" || len(s[0].Edits) == 0 {
+		t.Errorf("suggestions = %+v", s)
+	}
+	if findings[1].Suggestions != nil {
+		t.Errorf("findings[1] past the limit got suggestions")
+	}
+}
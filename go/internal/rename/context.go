@@ -3,8 +3,7 @@ package rename
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
-	"go/token"
+	"go/types"
 	"strings"
 )
 
@@ -16,33 +15,52 @@ type VarContext struct {
 	FunctionName    string
 	FunctionSummary string
 
-	VarName string
-	VarType string
-	Scope   string // function | file
-	Kind    string // local variable | parameter
+	VarName  string
+	VarType  string
+	Scope    string // function | closure | file
+	Kind     string // one of the varKind results, e.g. range value
+	Captured bool   // referenced from inside a function literal
+
+	MethodSet []string // methods callable on the variable
+	Calls     []string // signatures of functions the variable is passed to or assigned from
 
 	Assignments        []string
 	Usages             []string
+	Callers            []string // calls of the enclosing function
 	RelatedIdentifiers []string
 	Imports            []string
 	FileComments       []string
 }
 
-// BuildVarContext parses the file and builds a rich context for the variable
-func BuildVarContext(filename, funcName, varName string) (*VarContext, *ast.File, *token.FileSet, error) {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
-	if err != nil {
-		return nil, nil, nil, err
+// BuildVarContext builds a rich context for the variable ident refers to.
+// Usages and assignments are collected by object identity, so shadowed
+// variables and unrelated identifiers with the same name are ignored.
+func BuildVarContext(src *Source, ident *ast.Ident) (*VarContext, error) {
+	obj, ok := src.objectOf(ident).(*types.Var)
+	if !ok || obj.IsField() {
+		return nil, fmt.Errorf("%q is not a variable", ident.Name)
+	}
+
+	file := src.fileOf(obj.Pos())
+	if file == nil {
+		return nil, fmt.Errorf("declaration of %q is outside the loaded package", ident.Name)
 	}
 
 	ctx := &VarContext{
-		Filename:    filename,
-		VarName:     varName,
-		Scope:       "function",
-		Kind:        "local variable",
+		Filename:    src.Fset.Position(file.Pos()).Filename,
+		VarName:     obj.Name(),
+		VarType:     src.typeString(obj.Type()),
+		MethodSet:   src.methodSet(obj.Type()),
 		PackageName: file.Name.Name,
 	}
+	ctx.Kind, ctx.Scope = src.varKind(obj)
+
+	// a type switch declares its symbol anew in every clause
+	same := map[types.Object]bool{}
+	for _, b := range src.bindings(obj) {
+		same[b] = true
+	}
+	ctx.Captured = src.captured(obj)
 
 	// file-level comments
 	if file.Doc != nil {
@@ -56,13 +74,10 @@ func BuildVarContext(filename, funcName, varName string) (*VarContext, *ast.File
 		ctx.Imports = append(ctx.Imports, trimQuotes(imp.Path.Value))
 	}
 
-	// find the target function
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok || (funcName != "" && fn.Name.Name != funcName) {
-			continue
-		}
-
+	// the enclosing function bounds where the variable can be referenced
+	var scope []ast.Node
+	fn := enclosingFunc(src.pathTo(obj.Pos()))
+	if fn != nil {
 		ctx.FunctionName = fn.Name.Name
 		ctx.FunctionSummary = extractFuncSummary(fn)
 
@@ -74,32 +89,135 @@ func BuildVarContext(filename, funcName, varName string) (*VarContext, *ast.File
 				}
 			}
 		}
+		if fnObj := src.Info.Defs[fn.Name]; fnObj != nil {
+			ctx.Callers = src.callers(fnObj)
+		}
+		scope = []ast.Node{fn}
+	} else {
+		for _, f := range src.AllFiles() {
+			scope = append(scope, f)
+		}
+	}
 
-		// assignments & usages
-		ast.Inspect(fn.Body, func(n ast.Node) bool {
+	// assignments & usages
+	for _, node := range scope {
+		ast.Inspect(node, func(n ast.Node) bool {
 			switch x := n.(type) {
 			case *ast.AssignStmt:
 				for _, lhs := range x.Lhs {
-					if ident, ok := lhs.(*ast.Ident); ok && ident.Name == varName {
-						ctx.Assignments = append(ctx.Assignments, fmt.Sprintf("%s %s", x.Tok.String(), ident.Name))
+					if id, ok := lhs.(*ast.Ident); ok && same[src.Info.ObjectOf(id)] {
+						ctx.Assignments = append(ctx.Assignments, fmt.Sprintf("%s %s", x.Tok.String(), id.Name))
 					}
 				}
 			case *ast.Ident:
-				if x.Name == varName {
-					pos := fset.Position(x.Pos())
-					ctx.Usages = append(ctx.Usages, fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column))
+				if same[src.Info.ObjectOf(x)] {
+					ctx.Usages = append(ctx.Usages, src.position(x.Pos()))
 				}
 			}
 			return true
 		})
+		ctx.Calls = append(ctx.Calls, collectCalls(src, node, obj)...)
+	}
+
+	return ctx, nil
+}
+
+// varKind classifies v as "receiver", "parameter", "named result",
+// "range key", "range value", "type-switch binding", "select-case binding"
+// or "local variable", and names the scope declaring it: "function",
+// "closure" or "file".
+func (s *Source) varKind(v *types.Var) (kind, scope string) {
+	path := s.pathTo(v.Pos())
+
+	kind = "local variable"
+	if len(path) > 3 {
+		switch parent := path[1].(type) {
+		case *ast.Field:
+			list, _ := path[2].(*ast.FieldList)
+			switch owner := path[3].(type) {
+			case *ast.FuncDecl:
+				if owner.Recv == list {
+					kind = "receiver"
+				}
+			case *ast.FuncType:
+				if owner.Results == list {
+					kind = "named result"
+				} else {
+					kind = "parameter"
+				}
+			}
+		case *ast.RangeStmt:
+			if parent.Key == path[0] {
+				kind = "range key"
+			} else {
+				kind = "range value"
+			}
+		case *ast.AssignStmt:
+			switch path[2].(type) {
+			case *ast.TypeSwitchStmt:
+				kind = "type-switch binding"
+			case *ast.CommClause:
+				kind = "select-case binding"
+			}
+		}
+	}
+
+	scope = "file"
+	for _, n := range path {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return kind, "closure"
+		case *ast.FuncDecl:
+			return kind, "function"
+		}
+	}
+	return kind, scope
+}
 
-		// type inference
-		ctx.VarType = inferIdentTypeByName(fn.Body, varName)
+// bindings returns the variables a type switch symbol declares, one per
+// clause, if v is one of them, and just v otherwise.
+func (s *Source) bindings(v *types.Var) []types.Object {
+	ts := s.typeSwitchOf(v)
+	if ts == nil {
+		return []types.Object{v}
+	}
+	var objs []types.Object
+	for _, clause := range ts.Body.List {
+		if obj := s.Info.Implicits[clause]; obj != nil {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
 
-		return ctx, file, fset, nil
+// typeSwitchOf returns the type switch declaring v in its header, or nil.
+func (s *Source) typeSwitchOf(v *types.Var) *ast.TypeSwitchStmt {
+	path := s.pathTo(v.Pos())
+	if len(path) > 2 {
+		if ts, ok := path[2].(*ast.TypeSwitchStmt); ok && ts.Assign == path[1] {
+			return ts
+		}
 	}
+	return nil
+}
 
-	return nil, nil, nil, fmt.Errorf("function %q not found", funcName)
+// captured reports whether v is referenced from inside a function literal
+// that does not itself declare v.
+func (s *Source) captured(v *types.Var) bool {
+	file := s.fileOf(v.Pos())
+	if file == nil {
+		return false
+	}
+	for _, b := range s.bindings(v) {
+		for _, id := range s.refs(b, file) {
+			for _, n := range s.pathTo(id.Pos()) {
+				if lit, ok := n.(*ast.FuncLit); ok && (v.Pos() < lit.Pos() || lit.End() <= v.Pos()) {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 func extractFuncSummary(fn *ast.FuncDecl) string {
@@ -116,26 +234,50 @@ func trimQuotes(s string) string {
 	return s
 }
 
-// simple type inference stub
-func inferIdentTypeByName(block *ast.BlockStmt, name string) string {
-	var typ string
-	ast.Inspect(block, func(n ast.Node) bool {
-		assign, ok := n.(*ast.AssignStmt)
-		if !ok {
-			return true
-		}
-		for i, lhs := range assign.Lhs {
-			if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
-				if bl, ok := assign.Rhs[i].(*ast.BasicLit); ok {
-					typ = bl.Kind.String()
-					return false
+// collectCalls returns the signatures of the functions in node that take
+// obj as an argument or whose result is assigned to it.
+func collectCalls(src *Source, node ast.Node, obj types.Object) []string {
+	var calls []string
+	add := func(call *ast.CallExpr) {
+		calls = appendNew(calls, src.calleeString(call))
+	}
+	isObj := func(expr ast.Expr) bool {
+		id, ok := ast.Unparen(expr).(*ast.Ident)
+		return ok && src.Info.ObjectOf(id) == obj
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			for _, arg := range x.Args {
+				if isObj(arg) {
+					add(x)
+				}
+			}
+		case *ast.AssignStmt:
+			for i, lhs := range x.Lhs {
+				if !isObj(lhs) {
+					continue
+				}
+				rhs := x.Rhs[0]
+				if len(x.Rhs) == len(x.Lhs) {
+					rhs = x.Rhs[i]
+				}
+				if call, ok := ast.Unparen(rhs).(*ast.CallExpr); ok {
+					add(call)
+				}
+			}
+		case *ast.ValueSpec:
+			for i, name := range x.Names {
+				if i >= len(x.Values) || !isObj(name) {
+					continue
+				}
+				if call, ok := ast.Unparen(x.Values[i]).(*ast.CallExpr); ok {
+					add(call)
 				}
 			}
 		}
 		return true
 	})
-	if typ == "" {
-		typ = "unknown"
-	}
-	return typ
+	return calls
 }
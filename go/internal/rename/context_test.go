@@ -0,0 +1,212 @@
+package rename
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildVarContextTypes(t *testing.T) {
+	tests := []struct {
+		file, fn, v string
+		wantType    string
+		wantMethods []string
+		wantCalls   []string
+	}{
+		{
+			file: "fibonacci.go", fn: "Fibonacci", v: "num",
+			wantType:  "[]int",
+			wantCalls: []string{"func make([]int, int) []int"},
+		},
+		{
+			file: "order.go", fn: "PrintSummary", v: "msg",
+			wantType: "string",
+			wantCalls: []string{
+				"func fmt.Sprintf(format string, a ...any) string",
+				"func fmt.Println(a ...any) (n int, err error)",
+			},
+		},
+		{
+			file: "order.go", fn: "ApplyDiscount", v: "o",
+			wantType:    "*OrderStruct",
+			wantMethods: []string{"ApplyDiscount(pct float64) float64", "PrintSummary()"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fn+"/"+tt.v, func(t *testing.T) {
+			src, err := Load("../../testdata/"+tt.file, LoadOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ident, err := ResolveSelector(src, Selector{Kind: "funcvar", Func: tt.fn, Var: tt.v})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err := BuildVarContext(src, ident)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ctx.VarType != tt.wantType {
+				t.Errorf("VarType = %q, want %q", ctx.VarType, tt.wantType)
+			}
+			if !reflect.DeepEqual(ctx.MethodSet, tt.wantMethods) {
+				t.Errorf("MethodSet = %q, want %q", ctx.MethodSet, tt.wantMethods)
+			}
+			if !reflect.DeepEqual(ctx.Calls, tt.wantCalls) {
+				t.Errorf("Calls = %q, want %q", ctx.Calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// writeSource writes a single-file package to a temp dir and loads it.
+func writeSource(t *testing.T, code string) *Source {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "src.go")
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := Load(path, LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+// identAt returns the n-th (0-based) identifier named name in src.File.
+func identAt(t *testing.T, src *Source, name string, n int) *ast.Ident {
+	t.Helper()
+	var found *ast.Ident
+	ast.Inspect(src.File, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && id.Name == name && found == nil {
+			if n == 0 {
+				found = id
+			}
+			n--
+		}
+		return found == nil
+	})
+	if found == nil {
+		t.Fatalf("identifier %q not found", name)
+	}
+	return found
+}
+
+// lines extracts the line numbers from "file:line:col" usages.
+func lines(usages []string) []string {
+	var out []string
+	for _, u := range usages {
+		parts := strings.Split(u, ":")
+		out = append(out, parts[len(parts)-2])
+	}
+	return out
+}
+
+const shadowSrc = `package p
+
+func f(xs []int) int {
+	v := 0
+	for _, x := range xs {
+		v := x * 2
+		if v > 10 {
+			v := v - 10
+			_ = v
+		}
+		_ = v
+	}
+	return v
+}
+`
+
+func TestBuildVarContextShadowing(t *testing.T) {
+	src := writeSource(t, shadowSrc)
+
+	tests := []struct {
+		nth       int // which "v" identifier the cursor is on
+		wantLines []string
+		wantAssn  int
+	}{
+		{nth: 0, wantLines: []string{"4", "13"}, wantAssn: 1},           // outer v
+		{nth: 1, wantLines: []string{"6", "7", "8", "11"}, wantAssn: 1}, // loop-body v
+		{nth: 3, wantLines: []string{"8", "9"}, wantAssn: 1},            // innermost v
+	}
+
+	for _, tt := range tests {
+		ctx, err := BuildVarContext(src, identAt(t, src, "v", tt.nth))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lines(ctx.Usages); !reflect.DeepEqual(got, tt.wantLines) {
+			t.Errorf("v #%d: usage lines = %v, want %v", tt.nth, got, tt.wantLines)
+		}
+		if len(ctx.Assignments) != tt.wantAssn {
+			t.Errorf("v #%d: assignments = %v", tt.nth, ctx.Assignments)
+		}
+	}
+}
+
+const kindsSrc = `package p
+
+func f(xs []int, ch chan string) (total int) {
+	for i, x := range xs {
+		total += i * x
+	}
+	var val any = total
+	switch v := val.(type) {
+	case int:
+		total += v
+	case string:
+		_ = v
+	}
+	select {
+	case msg := <-ch:
+		_ = msg
+	}
+	count := 0
+	inc := func(step int) { count += step }
+	inc(1)
+	return total
+}
+`
+
+func TestBuildVarContextKinds(t *testing.T) {
+	src := writeSource(t, kindsSrc)
+
+	tests := []struct {
+		name, kind, scope string
+		captured          bool
+	}{
+		{name: "xs", kind: "parameter", scope: "function"},
+		{name: "total", kind: "named result", scope: "function"},
+		{name: "i", kind: "range key", scope: "function"},
+		{name: "x", kind: "range value", scope: "function"},
+		{name: "v", kind: "type-switch binding", scope: "function"},
+		{name: "msg", kind: "select-case binding", scope: "function"},
+		{name: "count", kind: "local variable", scope: "function", captured: true},
+		{name: "step", kind: "parameter", scope: "closure"},
+	}
+
+	for _, tt := range tests {
+		ctx, err := BuildVarContext(src, identAt(t, src, tt.name, 0))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ctx.Kind != tt.kind || ctx.Scope != tt.scope || ctx.Captured != tt.captured {
+			t.Errorf("%s: Kind = %q, Scope = %q, Captured = %v; want %q, %q, %v",
+				tt.name, ctx.Kind, ctx.Scope, ctx.Captured, tt.kind, tt.scope, tt.captured)
+		}
+	}
+
+	// every clause of the type switch declares its own v
+	ctx, err := BuildVarContext(src, identAt(t, src, "v", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lines(ctx.Usages); !reflect.DeepEqual(got, []string{"10", "12"}) {
+		t.Errorf("type-switch usages = %v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// DeclContext holds context for a constant or package-level variable to be
+// renamed
+type DeclContext struct {
+	PackageName string
+	Filename    string
+	Name        string
+	Kind        string // constant | variable
+	Scope       string // package | function
+	Type        string
+	Value       string // initializer as written, or inherited from the group for implicit constants
+	Doc         string
+
+	Group       []string // every name of the enclosing const (...) or var (...) block, with its value
+	IotaPattern string   // the iota expression the group repeats, e.g. "1 << iota"
+
+	References []string // "function (file:line:col)" for every use
+}
+
+// BuildDeclContext builds context for the constant or package-level
+// variable ident refers to.
+func BuildDeclContext(src *Source, ident *ast.Ident) (*DeclContext, error) {
+	obj := src.Info.ObjectOf(ident)
+	var kind string
+	switch o := obj.(type) {
+	case *types.Const:
+		kind = "constant"
+	case *types.Var:
+		if o.IsField() {
+			return nil, fmt.Errorf("%q is a struct field", ident.Name)
+		}
+		kind = "variable"
+	default:
+		return nil, fmt.Errorf("%q is not a constant or variable", ident.Name)
+	}
+
+	path := src.pathTo(obj.Pos())
+	var genDecl *ast.GenDecl
+	var spec *ast.ValueSpec
+	for _, n := range path {
+		switch x := n.(type) {
+		case *ast.ValueSpec:
+			if spec == nil {
+				spec = x
+			}
+		case *ast.GenDecl:
+			if genDecl == nil {
+				genDecl = x
+			}
+		}
+	}
+	if genDecl == nil || spec == nil {
+		return nil, fmt.Errorf("declaration of %q is outside the loaded package", ident.Name)
+	}
+
+	ctx := &DeclContext{
+		PackageName: obj.Pkg().Name(),
+		Filename:    src.Fset.Position(genDecl.Pos()).Filename,
+		Name:        obj.Name(),
+		Kind:        kind,
+		Scope:       "package",
+		Type:        src.typeString(obj.Type()),
+	}
+	if obj.Parent() != obj.Pkg().Scope() {
+		ctx.Scope = "function"
+	}
+	if spec.Doc != nil {
+		ctx.Doc = strings.TrimSpace(spec.Doc.Text())
+	} else if genDecl.Doc != nil {
+		ctx.Doc = strings.TrimSpace(genDecl.Doc.Text())
+	}
+
+	// Walk the block, tracking the expression list implicit constants
+	// repeat, so every member is shown with its effective value.
+	var inherited []ast.Expr
+	for _, s := range genDecl.Specs {
+		vs := s.(*ast.ValueSpec)
+		values := vs.Values
+		if genDecl.Tok == token.CONST {
+			if len(values) > 0 {
+				inherited = values
+			} else {
+				values = inherited
+			}
+		}
+		for i, name := range vs.Names {
+			var expr string
+			if i < len(values) {
+				expr = types.ExprString(values[i])
+			}
+			if name.Pos() == obj.Pos() {
+				ctx.Value = expr
+				if usesIota(values) {
+					ctx.IotaPattern = expr
+				}
+			}
+			if len(genDecl.Specs) > 1 || len(vs.Names) > 1 {
+				ctx.Group = append(ctx.Group, groupEntry(src, name, expr))
+			}
+		}
+	}
+
+	for _, id := range src.refs(obj, src.AllFiles()...) {
+		if id.Pos() == obj.Pos() {
+			continue
+		}
+		where := "package scope"
+		if fn := enclosingFunc(src.pathTo(id.Pos())); fn != nil {
+			where = fn.Name.Name
+		}
+		ctx.References = append(ctx.References, fmt.Sprintf("%s (%s)", where, src.position(id.Pos())))
+	}
+
+	return ctx, nil
+}
+
+// groupEntry renders one member of a const or var block as
+// "Name = expr" plus its constant value when that differs from expr.
+func groupEntry(src *Source, name *ast.Ident, expr string) string {
+	entry := name.Name
+	if expr != "" {
+		entry += " = " + expr
+	}
+	if c, ok := src.Info.Defs[name].(*types.Const); ok {
+		if val := c.Val().ExactString(); val != expr {
+			entry += " (" + val + ")"
+		}
+	}
+	return entry
+}
+
+// usesIota reports whether any of exprs mentions iota.
+func usesIota(exprs []ast.Expr) bool {
+	found := false
+	for _, e := range exprs {
+		ast.Inspect(e, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
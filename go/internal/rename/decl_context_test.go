@@ -0,0 +1,71 @@
+package rename
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const statusSrc = `package p
+
+// Status is the lifecycle state of an order.
+type Status int
+
+const (
+	StatusNew Status = iota
+	StatusPaid
+	StatusShipped
+)
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+)
+
+var defaultStatus = StatusNew
+
+func next(s Status) Status {
+	if s == StatusPaid {
+		return StatusShipped
+	}
+	return defaultStatus
+}
+`
+
+func TestBuildDeclContextIotaGroup(t *testing.T) {
+	src := writeSource(t, statusSrc)
+
+	ctx, err := BuildDeclContext(src, identAt(t, src, "StatusPaid", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Kind != "constant" || ctx.Scope != "package" || ctx.Type != "Status" {
+		t.Errorf("kind = %q, scope = %q, type = %q", ctx.Kind, ctx.Scope, ctx.Type)
+	}
+	if ctx.Value != "iota" || ctx.IotaPattern != "iota" {
+		t.Errorf("Value = %q, IotaPattern = %q", ctx.Value, ctx.IotaPattern)
+	}
+	want := []string{"StatusNew = iota (0)", "StatusPaid = iota (1)", "StatusShipped = iota (2)"}
+	if !reflect.DeepEqual(ctx.Group, want) {
+		t.Errorf("Group = %q, want %q", ctx.Group, want)
+	}
+	if len(ctx.References) != 1 || !strings.HasPrefix(ctx.References[0], "next (") {
+		t.Errorf("References = %q", ctx.References)
+	}
+
+	ctx, err = BuildDeclContext(src, identAt(t, src, "MB", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.IotaPattern != "1 << (10 * (iota + 1))" {
+		t.Errorf("IotaPattern = %q", ctx.IotaPattern)
+	}
+
+	ctx, err = BuildDeclContext(src, identAt(t, src, "defaultStatus", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Kind != "variable" || ctx.Value != "StatusNew" || len(ctx.Group) != 0 {
+		t.Errorf("ctx = %+v", ctx)
+	}
+}
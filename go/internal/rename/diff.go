@@ -0,0 +1,159 @@
+package rename
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// Diff renders edits as a unified diff of every file they touch, in the
+// order the files first appear in edits. Files are read from disk, so the
+// diff previews what ApplyEdits would write.
+func Diff(edits []Edit) (string, error) {
+	files, byFile := groupByFile(edits)
+
+	var b strings.Builder
+	for _, name := range files {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		out, err := applyToContent(content, byFile[name])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		b.WriteString(unifiedDiff(name, string(content), string(out)))
+	}
+	return b.String(), nil
+}
+
+// diffOp is one line of a line-by-line diff: ' ' kept, '-' removed or
+// '+' added.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff renders the changes from before to after as a unified diff
+// of the file name, or "" if they are equal.
+func unifiedDiff(name, before, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", name, name)
+
+	// old and new line numbers (0-based) at the start of each op
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if op.kind != '+' {
+			oldAt[i+1]++
+		}
+		if op.kind != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// grow the hunk while the next change is close enough to share
+		// context with this one
+		start := max(0, i-diffContext)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(len(ops), end+diffContext)
+
+		oldStart, oldCount := oldAt[start], oldAt[end]-oldAt[start]
+		newStart, newCount := newAt[start], newAt[end]-newAt[start]
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// splitLines splits s after each newline.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest line diff of a and b: common leading and
+// trailing lines are kept as they are, and the rest is matched by longest
+// common subsequence.
+func diffLines(a, b []string) []diffOp {
+	var prefix, suffix []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffOp{{' ', a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := prefix
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return append(ops, suffix...)
+}
@@ -0,0 +1,60 @@
+package rename
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[1] = "x := 1"
+	lines[3] = "_ = x"
+	lines[17] = "return x"
+	content := strings.Join(lines, "\n") + "\n"
+
+	path := filepath.Join(t.TempDir(), "a.go")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var edits []Edit
+	for _, n := range []int{1, 3, 17} {
+		off := strings.Index(content, lines[n]) + strings.Index(lines[n], "x")
+		edits = append(edits, Edit{Filename: path, Offset: off, OldText: "x", NewText: "n"})
+	}
+
+	got, err := Diff(edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--- " + path + "\n+++ " + path + "\n" +
+		"@@ -1,7 +1,7 @@\n" +
+		" line 1\n-x := 1\n+n := 1\n line 3\n-_ = x\n+_ = n\n line 5\n line 6\n line 7\n" +
+		"@@ -15,6 +15,6 @@\n" +
+		" line 15\n line 16\n line 17\n-return x\n+return n\n line 19\n line 20\n"
+	if got != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", got, want)
+	}
+
+	// the files are only read
+	if after, _ := os.ReadFile(path); string(after) != content {
+		t.Error("Diff modified the file")
+	}
+}
+
+func TestDiffLinesInsertDelete(t *testing.T) {
+	ops := diffLines([]string{"a\n", "b\n", "c\n"}, []string{"a\n", "c\n", "d\n"})
+	var got strings.Builder
+	for _, op := range ops {
+		got.WriteByte(op.kind)
+		got.WriteString(op.line)
+	}
+	if want := " a\n-b\n c\n+d\n"; got.String() != want {
+		t.Errorf("ops =\n%s\nwant\n%s", got.String(), want)
+	}
+}
@@ -0,0 +1,158 @@
+package rename
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"slices"
+)
+
+// Edit replaces OldText, found at byte Offset of Filename, with NewText.
+// Line and Column (1-based, Column in bytes) locate the same spot for
+// humans and editors.
+type Edit struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+	OldText  string
+	NewText  string
+}
+
+// identEdits returns edits replacing each of ids with newName, sorted by
+// file and offset. Identifiers already named newName are skipped.
+func (s *Source) identEdits(ids []*ast.Ident, newName string) []Edit {
+	var edits []Edit
+	for _, id := range ids {
+		if id.Name == newName {
+			continue
+		}
+		p := s.Fset.Position(id.Pos())
+		edits = append(edits, Edit{
+			Filename: p.Filename,
+			Offset:   p.Offset,
+			Line:     p.Line,
+			Column:   p.Column,
+			OldText:  id.Name,
+			NewText:  newName,
+		})
+	}
+	slices.SortFunc(edits, func(a, b Edit) int {
+		return cmp.Or(cmp.Compare(a.Filename, b.Filename), cmp.Compare(a.Offset, b.Offset))
+	})
+	return edits
+}
+
+// renameIdents returns every identifier that changes when obj is renamed.
+// A type switch symbol has no object of its own, so it is added for the
+// variables it declares.
+func (s *Source) renameIdents(obj types.Object) []*ast.Ident {
+	if tn, ok := obj.(*types.TypeName); ok {
+		return s.typeIdents(tn)
+	}
+	v, ok := obj.(*types.Var)
+	if !ok {
+		return s.refs(obj, s.AllFiles()...)
+	}
+	var ids []*ast.Ident
+	if ts := s.typeSwitchOf(v); ts != nil {
+		ids = append(ids, ts.Assign.(*ast.AssignStmt).Lhs[0].(*ast.Ident))
+	}
+	for _, b := range s.bindings(v) {
+		ids = append(ids, s.refs(b, s.AllFiles()...)...)
+	}
+	return ids
+}
+
+// typeIdents returns every identifier naming tn. A field embedding tn is
+// named after it, so the field and the selectors using it, as in
+// o.Inner, are renamed along with the type.
+func (s *Source) typeIdents(tn *types.TypeName) []*ast.Ident {
+	files := s.AllFiles()
+	ids := s.refs(tn, files...)
+	seen := map[*ast.Ident]bool{}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			id, ok := n.(*ast.Ident)
+			if !ok || s.Info.Uses[id] != tn {
+				return true
+			}
+			// Defs holds the embedded field, ObjectOf hides its Uses
+			if field, ok := s.Info.Defs[id].(*types.Var); ok && field.Embedded() {
+				for _, ref := range s.refs(field, files...) {
+					if !seen[ref] {
+						seen[ref] = true
+						ids = append(ids, ref)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return ids
+}
+
+// groupByFile splits edits by file, listing the files in the order they
+// first appear in edits.
+func groupByFile(edits []Edit) (files []string, byFile map[string][]Edit) {
+	byFile = map[string][]Edit{}
+	for _, e := range edits {
+		if _, ok := byFile[e.Filename]; !ok {
+			files = append(files, e.Filename)
+		}
+		byFile[e.Filename] = append(byFile[e.Filename], e)
+	}
+	return files, byFile
+}
+
+// ApplyEdits writes edits to disk. Every file is checked first: if the
+// text an edit replaces is no longer there, because the file changed since
+// it was loaded, nothing is written.
+func ApplyEdits(edits []Edit) error {
+	files, byFile := groupByFile(edits)
+
+	updated := map[string][]byte{}
+	for _, name := range files {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		out, err := applyToContent(content, byFile[name])
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		updated[name] = out
+	}
+
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, updated[name], info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyToContent applies edits of a single file to its content.
+func applyToContent(content []byte, edits []Edit) ([]byte, error) {
+	edits = slices.Clone(edits)
+	slices.SortFunc(edits, func(a, b Edit) int { return cmp.Compare(a.Offset, b.Offset) })
+
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		end := e.Offset + len(e.OldText)
+		if e.Offset < last || end > len(content) || string(content[e.Offset:end]) != e.OldText {
+			return nil, fmt.Errorf("%d:%d: expected %q; file changed since it was loaded", e.Line, e.Column, e.OldText)
+		}
+		out = append(out, content[last:e.Offset]...)
+		out = append(out, e.NewText...)
+		last = end
+	}
+	return append(out, content[last:]...), nil
+}
@@ -0,0 +1,149 @@
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestApplyToContent(t *testing.T) {
+	content := []byte("a := 1\nb := a + a\n")
+	edits := []Edit{
+		{Offset: 12, Line: 2, Column: 6, OldText: "a", NewText: "n"},
+		{Offset: 0, Line: 1, Column: 1, OldText: "a", NewText: "n"},
+		{Offset: 16, Line: 2, Column: 10, OldText: "a", NewText: "n"},
+	}
+	out, err := applyToContent(content, edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "n := 1\nb := n + n\n"; string(out) != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+
+	stale := []Edit{{Offset: 5, Line: 1, Column: 6, OldText: "a", NewText: "n"}}
+	if _, err := applyToContent(content, stale); err == nil || !strings.Contains(err.Error(), "changed since it was loaded") {
+		t.Errorf("stale edit: err = %v", err)
+	}
+}
+
+func TestRenameAppliesAcrossPackageAndTests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"total.go":      "package p\n\nfunc total(xs []int) (sum int) {\n\tfor _, x := range xs {\n\t\tsum += x\n\t}\n\treturn sum\n}\n",
+		"report.go":     "package p\n\nfunc report(xs []int) int { return total(xs) * 2 }\n",
+		"total_test.go": "package p\n\nimport \"testing\"\n\nfunc TestTotal(t *testing.T) {\n\tif total([]int{1, 2}) != 3 {\n\t\tt.Fail()\n\t}\n}\n",
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "total.go")
+	opts := Options{Load: LoadOptions{Tests: true}}
+
+	if _, err := Rename(path, Selector{Kind: "position", Row: 3, Col: 5}, "report", opts); err == nil ||
+		!strings.Contains(err.Error(), `collides with package-level func "report"`) {
+		t.Errorf("conflicting rename: err = %v", err)
+	}
+
+	result, err := Rename(path, Selector{Kind: "position", Row: 3, Col: 5}, "add", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyEdits(result.Suggestions[0].Edits); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"total.go":      "func add(xs []int) (sum int) {",
+		"report.go":     "return add(xs) * 2",
+		"total_test.go": "if add([]int{1, 2}) != 3 {",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), want) {
+			t.Errorf("%s:\n%s\nwant it to contain %q", name, got, want)
+		}
+	}
+}
+
+func TestRenameTypeUsedAsEmbeddedField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	code := "package p\n\ntype Inner struct{ N int }\n\ntype Outer struct {\n\tInner\n}\n\nfunc sum(o Outer) int { return o.Inner.N + o.N }\n"
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Rename(path, Selector{Kind: "position", Row: 3, Col: 5}, "Core", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyEdits(result.Suggestions[0].Edits); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package p\n\ntype Core struct{ N int }\n\ntype Outer struct {\n\tCore\n}\n\nfunc sum(o Outer) int { return o.Core.N + o.N }\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if src, err := Load(path, LoadOptions{}); err != nil || len(src.TypeErrors) > 0 {
+		t.Errorf("renamed code does not type-check: %v %v", err, src.TypeErrors)
+	}
+}
+
+func TestRenameRefusesEmbeddedField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	code := "package p\n\ntype Inner struct{ N int }\n\ntype Outer struct {\n\tInner\n}\n\nfunc sum(o Outer) int { return o.Inner.N }\n"
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Rename(path, Selector{Kind: "position", Row: 9, Col: 33}, "Core", Options{})
+	if err == nil || !strings.Contains(err.Error(), "embedded field") {
+		t.Errorf("Rename of an embedded field: got error %v", err)
+	}
+}
+
+func TestRenameWarnsAboutExportedNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	code := "package p\n\nfunc Use() int { return use() }\n\nfunc use() int { return 1 }\n"
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		row     int
+		newName string
+		opts    Options
+		warn    bool
+	}{
+		{3, "Apply", Options{}, true},
+		{3, "Apply", Options{Load: LoadOptions{Module: true}}, false},
+		{5, "apply", Options{}, false},
+	} {
+		result, err := Rename(path, Selector{Kind: "position", Row: tt.row, Col: 5}, tt.newName, tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		warned := slices.ContainsFunc(result.Warnings, func(w string) bool { return strings.Contains(w, "-module") })
+		if warned != tt.warn {
+			t.Errorf("row %d, module %v: warnings %q", tt.row, tt.opts.Load.Module, result.Warnings)
+		}
+	}
+}
+
+func TestRenameRefusesInterfaceMethod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p.go")
+	code := "package p\n\ntype Shape interface{ Area() int }\n\ntype Sq struct{ side int }\n\nfunc (s Sq) Area() int { return s.side * s.side }\n\nvar _ Shape = Sq{}\n"
+	if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Rename(path, Selector{Kind: "position", Row: 7, Col: 12}, "Size", Options{})
+	if err == nil || !strings.Contains(err.Error(), "Shape") {
+		t.Errorf("Rename of Sq.Area: got error %v, want one naming Shape", err)
+	}
+}
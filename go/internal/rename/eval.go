@@ -0,0 +1,127 @@
+package rename
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// EvalItem is one labeled identifier of an evaluation corpus: the
+// identifier at Row:Col (Col 0-based, as in a position Selector) of File
+// has been obfuscated, and Want lists the names a good suggestion matches,
+// starting with the original one.
+type EvalItem struct {
+	File string   `json:"file"` // relative to the corpus file
+	Row  int      `json:"row"`
+	Col  int      `json:"col"`
+	Want []string `json:"want"`
+}
+
+// EvalCase is the outcome of one EvalItem.
+type EvalCase struct {
+	EvalItem
+	Suggestions []string `json:"suggestions"`
+	Rejected    []string `json:"rejected,omitempty"`
+	Exact       bool     `json:"exact"` // the first suggestion is wanted
+	Top3        bool     `json:"top3"`  // one of the first three is
+	LatencyMS   int64    `json:"latencyMs"`
+	Err         string   `json:"error,omitempty"`
+}
+
+// EvalReport summarizes an evaluation run. Rates are fractions of the
+// items, except ValidityRate, the fraction of suggested names that passed
+// validation. Latency covers the whole pipeline of an item, loading
+// included.
+type EvalReport struct {
+	Provider      string     `json:"provider"`
+	Corpus        string     `json:"corpus"`
+	Items         int        `json:"items"`
+	Errors        int        `json:"errors"`
+	ExactMatch    float64    `json:"exactMatch"`
+	Top3HitRate   float64    `json:"top3HitRate"`
+	ValidityRate  float64    `json:"validityRate"`
+	MeanLatencyMS int64      `json:"meanLatencyMs"`
+	MaxLatencyMS  int64      `json:"maxLatencyMs"`
+	Cases         []EvalCase `json:"cases"`
+}
+
+// LoadCorpus reads a JSON array of EvalItems.
+func LoadCorpus(path string) ([]EvalItem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []EvalItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, item := range items {
+		if item.File == "" || len(item.Want) == 0 {
+			return nil, fmt.Errorf("%s: item %d needs a file and at least one wanted name", path, i+1)
+		}
+	}
+	return items, nil
+}
+
+// Evaluate runs every item of the corpus at corpusPath through Run with
+// provider and scores the suggestions against the wanted names.
+func Evaluate(corpusPath string, provider Provider, opts Options) (*EvalReport, error) {
+	items, err := LoadCorpus(corpusPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &EvalReport{Provider: provider.Name(), Corpus: corpusPath, Items: len(items)}
+	var exact, top3, valid, suggested int
+	var total time.Duration
+	for _, item := range items {
+		c := EvalCase{EvalItem: item, Suggestions: []string{}}
+		filename := filepath.Join(filepath.Dir(corpusPath), item.File)
+		selector := Selector{Kind: "position", Row: item.Row, Col: item.Col}
+
+		start := time.Now()
+		result, err := Run(filename, selector, provider, opts)
+		elapsed := time.Since(start)
+		total += elapsed
+		c.LatencyMS = elapsed.Milliseconds()
+		report.MaxLatencyMS = max(report.MaxLatencyMS, c.LatencyMS)
+
+		if err != nil {
+			c.Err = err.Error()
+			report.Errors++
+		} else {
+			for _, s := range result.Suggestions {
+				c.Suggestions = append(c.Suggestions, s.Name)
+			}
+			for _, r := range result.Rejected {
+				c.Rejected = append(c.Rejected, r.Name)
+			}
+			c.Exact = len(c.Suggestions) > 0 && slices.Contains(item.Want, c.Suggestions[0])
+			c.Top3 = slices.ContainsFunc(c.Suggestions[:min(3, len(c.Suggestions))], func(name string) bool {
+				return slices.Contains(item.Want, name)
+			})
+			valid += len(c.Suggestions)
+			suggested += len(c.Suggestions) + len(c.Rejected)
+		}
+		if c.Exact {
+			exact++
+		}
+		if c.Top3 {
+			top3++
+		}
+		report.Cases = append(report.Cases, c)
+	}
+
+	if n := len(items); n > 0 {
+		report.ExactMatch = float64(exact) / float64(n)
+		report.Top3HitRate = float64(top3) / float64(n)
+		report.MeanLatencyMS = (total / time.Duration(n)).Milliseconds()
+	}
+	if suggested > 0 {
+		report.ValidityRate = float64(valid) / float64(suggested)
+	}
+	return report, nil
+}
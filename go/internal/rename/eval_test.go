@@ -0,0 +1,74 @@
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluateHeuristicBaseline(t *testing.T) {
+	const corpus = "../../testdata/eval/corpus.json"
+	items, err := LoadCorpus(corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Evaluate(corpus, HeuristicProvider{}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the scores themselves belong in the JSON report, not here
+	if report.Provider != "heuristic" || report.Items != len(items) || len(report.Cases) != len(items) {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, rate := range []float64{report.ExactMatch, report.Top3HitRate, report.ValidityRate} {
+		if rate < 0 || rate > 1 {
+			t.Errorf("rate %v out of [0, 1] in %+v", rate, report)
+		}
+	}
+	failed := 0
+	for i, c := range report.Cases {
+		if c.File != items[i].File || c.Row != items[i].Row || c.Col != items[i].Col {
+			t.Errorf("cases[%d] = %+v, want item %+v", i, c, items[i])
+		}
+		if c.Err != "" {
+			failed++
+		} else if len(c.Suggestions) == 0 {
+			t.Errorf("cases[%d] has neither suggestions nor an error", i)
+		}
+	}
+	if failed != report.Errors {
+		t.Errorf("%d cases failed, report counts %d", failed, report.Errors)
+	}
+}
+
+func TestEvaluateScores(t *testing.T) {
+	dir := t.TempDir()
+	code := "package p\n\nfunc f(x []string) int {\n\ty := len(x)\n\treturn y\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	corpus := `[
+		{"file": "p.go", "row": 3, "col": 7, "want": ["names"]},
+		{"file": "p.go", "row": 4, "col": 1, "want": ["n", "count"]}
+	]`
+	if err := os.WriteFile(filepath.Join(dir, "corpus.json"), []byte(corpus), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{replies: []string{
+		`[{"name":"names","reason":"a"},{"name":"strs","reason":"b"},{"name":"len","reason":"c"}]`,
+		`[{"name":"num","reason":"a"},{"name":"x","reason":"b"},{"name":"count","reason":"c"}]`,
+	}}
+
+	report, err := Evaluate(filepath.Join(dir, "corpus.json"), p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x as a name for y collides with the parameter; count is wanted but
+	// comes after num
+	if report.ExactMatch != 0.5 || report.Top3HitRate != 1 || report.ValidityRate != 4.0/6 {
+		t.Errorf("report = %+v", report)
+	}
+	if c := report.Cases[1]; len(c.Suggestions) != 2 || c.Rejected[0] != "x" || c.Exact || !c.Top3 {
+		t.Errorf("cases[1] = %+v", c)
+	}
+}
@@ -3,8 +3,7 @@ package rename
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
-	"go/token"
+	"go/types"
 	"strings"
 )
 
@@ -16,85 +15,60 @@ type FieldContext struct {
 	FieldName   string
 	FieldType   string
 	StructDoc   string
-	Usages      []string // "filepath:line:col", 1-based — declaration first, then selector sites
+	Usages      []string // "filepath:line:col", 1-based — declaration first, then selector sites across the loaded packages
 }
 
-// BuildFieldContext parses the file and builds context for a struct field
-func BuildFieldContext(filename, structName, fieldName string) (*FieldContext, *ast.File, *token.FileSet, error) {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
-	if err != nil {
-		return nil, nil, nil, err
+// BuildFieldContext builds context for the struct field ident refers to.
+// Only selectors and composite-literal keys that resolve to that very field
+// are recorded, so same-named fields of other structs are ignored.
+func BuildFieldContext(src *Source, ident *ast.Ident) (*FieldContext, error) {
+	obj, ok := src.Info.ObjectOf(ident).(*types.Var)
+	if !ok || !obj.IsField() {
+		return nil, fmt.Errorf("%q is not a struct field", ident.Name)
+	}
+
+	file := src.fileOf(obj.Pos())
+	if file == nil {
+		return nil, fmt.Errorf("declaration of field %q is outside the loaded package", ident.Name)
 	}
 
 	ctx := &FieldContext{
-		Filename:    filename,
+		Filename:    src.Fset.Position(file.Pos()).Filename,
 		PackageName: file.Name.Name,
-		FieldName:   fieldName,
-		StructName:  structName,
+		FieldName:   obj.Name(),
+		FieldType:   src.typeString(obj.Type()),
 	}
 
-	// Find the struct declaration and record the field's declaration position
-	found := false
-	for _, decl := range file.Decls {
-		genDecl, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
-		}
-		for _, spec := range genDecl.Specs {
-			typeSpec, ok := spec.(*ast.TypeSpec)
-			if !ok || typeSpec.Name.Name != structName {
-				continue
-			}
-			structType, ok := typeSpec.Type.(*ast.StructType)
-			if !ok {
-				continue
-			}
-			if genDecl.Doc != nil {
-				ctx.StructDoc = strings.TrimSpace(genDecl.Doc.Text())
-			}
-			for _, field := range structType.Fields.List {
-				for _, name := range field.Names {
-					if name.Name == fieldName {
-						ctx.FieldType = fieldTypeStr(field.Type)
-						pos := fset.Position(name.Pos())
-						ctx.Usages = append(ctx.Usages, fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column))
-						found = true
-					}
-				}
-			}
+	// Find the struct declaration the field belongs to
+	for _, n := range src.pathTo(obj.Pos()) {
+		if typeSpec, ok := n.(*ast.TypeSpec); ok {
+			ctx.StructName = typeSpec.Name.Name
+			ctx.StructDoc = typeDoc(src.pathTo(typeSpec.Pos()), typeSpec)
+			break
 		}
 	}
 
-	if !found {
-		return nil, nil, nil, fmt.Errorf("field %q not found in struct %q", fieldName, structName)
-	}
-
-	// Collect all selector expression usages (x.FieldName) throughout the file
-	ast.Inspect(file, func(n ast.Node) bool {
-		sel, ok := n.(*ast.SelectorExpr)
-		if !ok {
-			return true
+	// Declaration first, then every reference to the same field object
+	ctx.Usages = append(ctx.Usages, src.position(obj.Pos()))
+	for _, id := range src.refs(obj, src.AllFiles()...) {
+		if id.Pos() != obj.Pos() {
+			ctx.Usages = append(ctx.Usages, src.position(id.Pos()))
 		}
-		if sel.Sel.Name == fieldName {
-			pos := fset.Position(sel.Sel.Pos())
-			ctx.Usages = append(ctx.Usages, fmt.Sprintf("%s:%d:%d", pos.Filename, pos.Line, pos.Column))
-		}
-		return true
-	})
+	}
 
-	return ctx, file, fset, nil
+	return ctx, nil
 }
 
-func fieldTypeStr(expr ast.Expr) string {
-	switch t := expr.(type) {
-	case *ast.Ident:
-		return t.Name
-	case *ast.StarExpr:
-		return "*" + fieldTypeStr(t.X)
-	case *ast.ArrayType:
-		return "[]" + fieldTypeStr(t.Elt)
-	default:
-		return "unknown"
+// typeDoc returns the doc comment of typeSpec, falling back to the doc of
+// its enclosing declaration found on path.
+func typeDoc(path []ast.Node, typeSpec *ast.TypeSpec) string {
+	if typeSpec.Doc != nil {
+		return strings.TrimSpace(typeSpec.Doc.Text())
+	}
+	for _, n := range path {
+		if genDecl, ok := n.(*ast.GenDecl); ok && genDecl.Doc != nil {
+			return strings.TrimSpace(genDecl.Doc.Text())
+		}
 	}
+	return ""
 }
@@ -0,0 +1,50 @@
+package rename
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sharedFieldSrc = `package p
+
+type Order struct {
+	Total float64
+}
+
+type Invoice struct {
+	Total float64
+}
+
+func (o Order) Total2() float64 { return o.Total * 2 }
+
+func sum(o Order, i Invoice) float64 {
+	inv := Invoice{Total: 1}
+	return o.Total + i.Total + inv.Total
+}
+`
+
+func TestBuildFieldContextSharedName(t *testing.T) {
+	src := writeSource(t, sharedFieldSrc)
+
+	tests := []struct {
+		nth        int
+		wantStruct string
+		wantLines  []string
+	}{
+		{nth: 0, wantStruct: "Order", wantLines: []string{"4", "11", "15"}},
+		{nth: 1, wantStruct: "Invoice", wantLines: []string{"8", "14", "15", "15"}},
+	}
+
+	for _, tt := range tests {
+		ctx, err := BuildFieldContext(src, identAt(t, src, "Total", tt.nth))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ctx.StructName != tt.wantStruct {
+			t.Errorf("Total #%d: StructName = %q, want %q", tt.nth, ctx.StructName, tt.wantStruct)
+		}
+		if got := lines(ctx.Usages); !reflect.DeepEqual(got, tt.wantLines) {
+			t.Errorf("Total #%d: usage lines = %v, want %v", tt.nth, got, tt.wantLines)
+		}
+	}
+}
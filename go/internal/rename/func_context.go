@@ -0,0 +1,158 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// FuncContext holds context for a function or method to be renamed
+type FuncContext struct {
+	PackageName string
+	Filename    string
+	FuncName    string
+	Receiver    string // receiver type for methods, e.g. *OrderStruct
+	Signature   string // e.g. func(pct float64) float64
+	Doc         string
+
+	Statements int      // top-level statements in the body
+	Calls      []string // signatures of the functions the body calls
+	Returns    []string // expressions the body returns
+
+	CallSites  []string // "caller: call (file:line:col)"
+	Implements []string // interfaces the method helps satisfy, e.g. fmt.Stringer
+}
+
+// BuildFuncContext builds context for the function or method ident refers to.
+func BuildFuncContext(src *Source, ident *ast.Ident) (*FuncContext, error) {
+	fn, ok := src.Info.ObjectOf(ident).(*types.Func)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a function", ident.Name)
+	}
+
+	var decl *ast.FuncDecl
+	for _, n := range src.pathTo(fn.Pos()) {
+		if d, ok := n.(*ast.FuncDecl); ok && d.Name.Pos() == fn.Pos() {
+			decl = d
+			break
+		}
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Recv() != nil && types.IsInterface(sig.Recv().Type()) {
+		return nil, fmt.Errorf("%q is an interface method", ident.Name)
+	}
+	if decl == nil {
+		return nil, fmt.Errorf("declaration of %q is outside the loaded package", ident.Name)
+	}
+
+	ctx := &FuncContext{
+		PackageName: fn.Pkg().Name(),
+		Filename:    src.Fset.Position(decl.Pos()).Filename,
+		FuncName:    fn.Name(),
+		Signature:   src.typeString(sig),
+		Doc:         extractFuncSummary(decl),
+		CallSites:   src.callers(fn),
+	}
+	if recv := sig.Recv(); recv != nil {
+		ctx.Receiver = src.typeString(recv.Type())
+		ctx.Implements = src.implementedBy(fn)
+	}
+
+	if decl.Body != nil {
+		ctx.Statements = len(decl.Body.List)
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.FuncLit:
+				// returns inside closures belong to the closure
+				ast.Inspect(x.Body, func(n ast.Node) bool {
+					if call, ok := n.(*ast.CallExpr); ok {
+						ctx.Calls = appendNew(ctx.Calls, src.calleeString(call))
+					}
+					return true
+				})
+				return false
+			case *ast.CallExpr:
+				ctx.Calls = appendNew(ctx.Calls, src.calleeString(x))
+			case *ast.ReturnStmt:
+				var results []string
+				for _, r := range x.Results {
+					results = append(results, types.ExprString(r))
+				}
+				if len(results) > 0 {
+					ctx.Returns = appendNew(ctx.Returns, strings.Join(results, ", "))
+				}
+			}
+			return true
+		})
+	}
+
+	return ctx, nil
+}
+
+// implementedBy lists the interfaces, declared in the loaded or directly
+// imported packages, that declare a method named like method and that the
+// method's receiver type implements.
+func (s *Source) implementedBy(method *types.Func) []string {
+	recv := method.Type().(*types.Signature).Recv().Type()
+	if types.IsInterface(recv) {
+		return nil
+	}
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	ptr := types.NewPointer(recv)
+
+	pkgs := map[*types.Package]bool{s.Pkg: true}
+	for _, imp := range s.Pkg.Imports() {
+		pkgs[imp] = true
+	}
+	for _, f := range s.Others {
+		if obj := s.Info.Defs[f.Name]; obj != nil {
+			pkgs[obj.Pkg()] = true
+		}
+	}
+
+	var out []string
+	check := func(name string, iface *types.Interface) {
+		for i := 0; i < iface.NumMethods(); i++ {
+			if iface.Method(i).Name() == method.Name() && (types.Implements(recv, iface) || types.Implements(ptr, iface)) {
+				out = append(out, name)
+				return
+			}
+		}
+	}
+
+	check("error", types.Universe.Lookup("error").Type().Underlying().(*types.Interface))
+	for pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || (!tn.Exported() && pkg != s.Pkg) {
+				continue
+			}
+			if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+				check(types.TypeString(tn.Type(), s.qualifier()), iface)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// appendNew appends s unless it is empty or already present.
+func appendNew(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,76 @@
+package rename
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuildFuncContextMethod(t *testing.T) {
+	src, err := Load("../../testdata/order.go", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ident, err := ResolveSelector(src, Selector{Kind: "position", Row: 14, Col: 23})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := BuildFuncContext(src, ident)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.FuncName != "ApplyDiscount" || ctx.Receiver != "*OrderStruct" {
+		t.Errorf("name = %q, receiver = %q", ctx.FuncName, ctx.Receiver)
+	}
+	if ctx.Signature != "func(pct float64) float64" {
+		t.Errorf("Signature = %q", ctx.Signature)
+	}
+	if ctx.Doc != "This function calculates a discount on the order total" {
+		t.Errorf("Doc = %q", ctx.Doc)
+	}
+	if ctx.Statements != 3 || !reflect.DeepEqual(ctx.Returns, []string{"discount"}) {
+		t.Errorf("Statements = %d, Returns = %q", ctx.Statements, ctx.Returns)
+	}
+}
+
+const stringerSrc = `package p
+
+import "fmt"
+
+type Celsius float64
+
+func (c Celsius) String() string { return fmt.Sprintf("%.1f°C", float64(c)) }
+
+func (c Celsius) Fahrenheit() float64 { return float64(c)*9/5 + 32 }
+
+func report(c Celsius) {
+	fmt.Println(c.String(), c.Fahrenheit())
+}
+`
+
+func TestBuildFuncContextInterfaces(t *testing.T) {
+	src := writeSource(t, stringerSrc)
+
+	ctx, err := BuildFuncContext(src, identAt(t, src, "String", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ctx.Implements, []string{"fmt.Stringer"}) {
+		t.Errorf("Implements = %q", ctx.Implements)
+	}
+	if want := []string{"func fmt.Sprintf(format string, a ...any) string"}; !reflect.DeepEqual(ctx.Calls, want) {
+		t.Errorf("Calls = %q, want %q", ctx.Calls, want)
+	}
+	if len(ctx.CallSites) != 1 || !strings.HasPrefix(ctx.CallSites[0], "report: c.String() (") {
+		t.Errorf("CallSites = %q", ctx.CallSites)
+	}
+
+	ctx, err = BuildFuncContext(src, identAt(t, src, "Fahrenheit", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ctx.Implements) != 0 {
+		t.Errorf("Implements = %q, want none", ctx.Implements)
+	}
+}
@@ -0,0 +1,84 @@
+package rename
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files from the current output")
+
+// goldenDir holds one .golden file per case of TestGolden: the context
+// built for the selected identifier and the prompt built from it.
+const goldenDir = "../../testdata/golden"
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		selector Selector
+	}{
+		{"sample_data", "sample.go", Selector{Kind: "position", Row: 9, Col: 18}},
+		{"sample_count", "sample.go", Selector{Kind: "funcvar", Func: "ComputeStats", Var: "count"}},
+		{"sample_range_value", "sample.go", Selector{Kind: "position", Row: 11, Col: 8}},
+		{"sample_func", "sample.go", Selector{Kind: "position", Row: 9, Col: 5}},
+		{"order_type", "order.go", Selector{Kind: "position", Row: 6, Col: 5}},
+		{"order_field", "order.go", Selector{Kind: "position", Row: 10, Col: 1}},
+		{"order_receiver", "order.go", Selector{Kind: "position", Row: 14, Col: 6}},
+		{"order_method", "order.go", Selector{Kind: "position", Row: 14, Col: 22}},
+		{"order_param", "order.go", Selector{Kind: "position", Row: 14, Col: 36}},
+		{"order_msg", "order.go", Selector{Kind: "position", Row: 22, Col: 1}},
+		{"order_import", "order.go", Selector{Kind: "position", Row: 22, Col: 8}},
+		{"fibonacci_num", "fibonacci.go", Selector{Kind: "position", Row: 10, Col: 1}},
+		{"fibonacci_loop_var", "fibonacci.go", Selector{Kind: "position", Row: 13, Col: 5}},
+		{"fibonacci_func", "fibonacci.go", Selector{Kind: "position", Row: 9, Col: 5}},
+	}
+
+	sources := map[string]*Source{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := sources[tt.file]
+			if src == nil {
+				var err error
+				if src, err = Load(filepath.Join("../../testdata", tt.file), LoadOptions{}); err != nil {
+					t.Fatal(err)
+				}
+				sources[tt.file] = src
+			}
+			ident, err := ResolveSelector(src, tt.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+			target, err := prepare(src, ident)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, err := json.MarshalIndent(target.context, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := fmt.Sprintf("-- context (%T) --\n%s\n-- prompt --\n%s", target.context, ctx, target.prompt)
+
+			path := filepath.Join(goldenDir, tt.name+".golden")
+			if *update {
+				if err := os.MkdirAll(goldenDir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v; run go test -run TestGolden -update to create it", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s differs from the current output (run go test -run TestGolden -update to accept it):\n%s", path, unifiedDiff(path, string(want), got))
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package rename
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// GroupContext holds context for renaming a const block, and the named
+// type its members share, as one unit
+type GroupContext struct {
+	PackageName string
+	TypeName    string // named type of the members, "" if they have none
+	TypeDoc     string
+	Members     []string // "Name = expr (value)" for every constant in the block
+	Names       []string // every name to map, type first
+	References  []string // "Name: N references"
+
+	objects []types.Object // parallel to Names
+}
+
+// BuildGroupContext builds the group for ident, which may be any constant
+// of a const (...) block or the named type of such a block.
+func BuildGroupContext(src *Source, ident *ast.Ident) (*GroupContext, error) {
+	var block *ast.GenDecl
+	switch obj := src.Info.ObjectOf(ident).(type) {
+	case *types.Const:
+		for _, n := range src.pathTo(obj.Pos()) {
+			if gd, ok := n.(*ast.GenDecl); ok && gd.Tok == token.CONST {
+				block = gd
+				break
+			}
+		}
+	case *types.TypeName:
+		block = src.constBlockOf(obj)
+	}
+	if block == nil || len(block.Specs) < 2 {
+		return nil, fmt.Errorf("%q is not part of a const (...) block", ident.Name)
+	}
+
+	ctx := &GroupContext{PackageName: src.Pkg.Name()}
+
+	var consts []*types.Const
+	var members []string
+	var inherited []ast.Expr
+	for _, s := range block.Specs {
+		vs := s.(*ast.ValueSpec)
+		values := vs.Values
+		if len(values) > 0 {
+			inherited = values
+		} else {
+			values = inherited
+		}
+		for i, name := range vs.Names {
+			c, ok := src.Info.Defs[name].(*types.Const)
+			if !ok || name.Name == "_" {
+				continue
+			}
+			var expr string
+			if i < len(values) {
+				expr = types.ExprString(values[i])
+			}
+			consts = append(consts, c)
+			members = append(members, groupEntry(src, name, expr))
+		}
+	}
+	if len(consts) == 0 {
+		return nil, fmt.Errorf("const block of %q has no named constants", ident.Name)
+	}
+	ctx.Members = members
+
+	// A named type shared by every member, and declared in this package,
+	// is renamed together with them.
+	if named, ok := consts[0].Type().(*types.Named); ok && named.Obj().Pkg() == src.Pkg {
+		shared := true
+		for _, c := range consts[1:] {
+			if !types.Identical(c.Type(), named) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			tn := named.Obj()
+			ctx.TypeName = tn.Name()
+			if ts := src.typeSpecOf(tn); ts != nil {
+				ctx.TypeDoc = typeDoc(src.pathTo(ts.Pos()), ts)
+			}
+			ctx.Names = append(ctx.Names, tn.Name())
+			ctx.objects = append(ctx.objects, tn)
+		}
+	}
+	for _, c := range consts {
+		ctx.Names = append(ctx.Names, c.Name())
+		ctx.objects = append(ctx.objects, c)
+	}
+
+	for i, obj := range ctx.objects {
+		n := len(src.refs(obj, src.AllFiles()...)) - 1 // minus the declaration
+		ctx.References = append(ctx.References, fmt.Sprintf("%s: %d references", ctx.Names[i], n))
+	}
+
+	return ctx, nil
+}
+
+// constBlockOf returns the first const (...) block whose members all have
+// type tn.
+func (s *Source) constBlockOf(tn *types.TypeName) *ast.GenDecl {
+	for _, f := range s.Files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.CONST || len(gd.Specs) < 2 {
+				continue
+			}
+			all := true
+			for _, spec := range gd.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if c, ok := s.Info.Defs[name].(*types.Const); !ok || !types.Identical(c.Type(), tn.Type()) {
+						all = false
+					}
+				}
+			}
+			if all {
+				return gd
+			}
+		}
+	}
+	return nil
+}
+
+// validateGroup reports why suggestion cannot be applied to the group, or
+// nil if it can. Members may keep their current name, but every name must
+// be mapped and no two may end up the same.
+func validateGroup(src *Source, ctx *GroupContext, suggestion Suggestion) error {
+	old := map[string]bool{}
+	for _, name := range ctx.Names {
+		old[name] = true
+	}
+
+	taken := map[string]string{}
+	for i, name := range ctx.Names {
+		newName, ok := suggestion.Mapping[name]
+		if !ok {
+			return fmt.Errorf("no new name for %q", name)
+		}
+		if prev, dup := taken[newName]; dup {
+			return fmt.Errorf("%q and %q both renamed to %q", prev, name, newName)
+		}
+		taken[newName] = name
+		if newName == name {
+			continue
+		}
+
+		// the other members are renamed too, so their old names are free
+		scope := nameScope{}
+		for n, what := range objectScope(src, ctx.objects[i]) {
+			if !old[n] {
+				scope[n] = what
+			}
+		}
+		if err := validateName(name, newName, packageMember(ctx.objects[i]), scope); err != nil {
+			return fmt.Errorf("%s→%s: %v", name, newName, err)
+		}
+	}
+	return nil
+}
+
+// suggestValidGroup is suggestValid for group renames. selected is the
+// name under the cursor, whose new name becomes each suggestion's Name.
+func suggestValidGroup(src *Source, ctx *GroupContext, prompt, selected string, provider Provider) ([]Suggestion, []Rejection, error) {
+	const maxRequests = 2
+
+	var rejected []Rejection
+	for attempt := 1; attempt <= maxRequests; attempt++ {
+		p := prompt
+		if len(rejected) > 0 {
+			p += rejectionNote(rejected)
+		}
+		suggestions, err := callLLM(p, provider, groupSchema, parseGroupSuggestions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var valid []Suggestion
+		for _, s := range suggestions {
+			s.Name = s.Mapping[selected]
+			if err := validateGroup(src, ctx, s); err != nil {
+				rejected = append(rejected, Rejection{Name: mappingString(ctx.Names, s.Mapping), Reason: err.Error()})
+				continue
+			}
+			valid = append(valid, s)
+		}
+		if len(valid) > 0 {
+			return valid, rejected, nil
+		}
+	}
+
+	return nil, rejected, fmt.Errorf("no valid group suggestions from LLM; %d rejected", len(rejected))
+}
+
+// edits renames every member of the group as mapping says.
+func (ctx *GroupContext) edits(src *Source, mapping map[string]string) []Edit {
+	var edits []Edit
+	for i, obj := range ctx.objects {
+		edits = append(edits, src.identEdits(src.refs(obj, src.AllFiles()...), mapping[ctx.Names[i]])...)
+	}
+	slices.SortFunc(edits, func(a, b Edit) int {
+		return cmp.Or(cmp.Compare(a.Filename, b.Filename), cmp.Compare(a.Offset, b.Offset))
+	})
+	return edits
+}
+
+// mappingString renders a mapping in group order, e.g. "A→B, C→D".
+func mappingString(names []string, mapping map[string]string) string {
+	var parts []string
+	for _, n := range names {
+		if v, ok := mapping[n]; ok {
+			parts = append(parts, n+"→"+v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,83 @@
+package rename
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunGroup(t *testing.T) {
+	src := writeSource(t, statusSrc)
+	path := src.Fset.Position(src.File.Pos()).Filename
+
+	p := &stubProvider{replies: []string{`[
+		{"names": [
+			{"old": "Status", "new": "OrderState"},
+			{"old": "StatusNew", "new": "OrderStateNew"},
+			{"old": "StatusPaid", "new": "OrderStatePaid"},
+			{"old": "StatusShipped", "new": "OrderStateShipped"}
+		], "reason": "order lifecycle states"},
+		{"names": {"Status": "State", "StatusNew": "StateNew", "StatusPaid": "StateNew", "StatusShipped": "StateShipped"},
+		 "reason": "shorter"},
+		{"names": [{"old": "StatusNew", "new": "New"}], "reason": "incomplete"}
+	]`}}
+
+	// cursor on StatusPaid (line 8, column 1)
+	result, err := Run(path, Selector{Kind: "position", Row: 8, Col: 1}, p, Options{Group: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Suggestions) != 1 {
+		t.Fatalf("suggestions = %+v", result.Suggestions)
+	}
+	got := result.Suggestions[0]
+	want := map[string]string{
+		"Status":        "OrderState",
+		"StatusNew":     "OrderStateNew",
+		"StatusPaid":    "OrderStatePaid",
+		"StatusShipped": "OrderStateShipped",
+	}
+	if got.Name != "OrderStatePaid" || !reflect.DeepEqual(got.Mapping, want) {
+		t.Errorf("suggestion = %+v", got)
+	}
+
+	if len(result.Rejected) != 2 {
+		t.Fatalf("rejected = %+v", result.Rejected)
+	}
+	if !strings.Contains(result.Rejected[0].Reason, `"StatusNew" and "StatusPaid" both renamed to "StateNew"`) {
+		t.Errorf("rejection = %+v", result.Rejected[0])
+	}
+	if !strings.Contains(result.Rejected[1].Reason, `no new name for "Status"`) {
+		t.Errorf("rejection = %+v", result.Rejected[1])
+	}
+
+	if !strings.Contains(result.Debug.Prompt, "Names to map:\n- Status\n- StatusNew\n- StatusPaid\n- StatusShipped\n") {
+		t.Errorf("prompt does not list the group:\n%s", result.Debug.Prompt)
+	}
+}
+
+func TestBuildGroupContextFromType(t *testing.T) {
+	src := writeSource(t, statusSrc)
+
+	ctx, err := BuildGroupContext(src, identAt(t, src, "Status", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.TypeName != "Status" || ctx.TypeDoc != "Status is the lifecycle state of an order." {
+		t.Errorf("type = %q, doc = %q", ctx.TypeName, ctx.TypeDoc)
+	}
+	wantRefs := []string{"Status: 3 references", "StatusNew: 1 references", "StatusPaid: 1 references", "StatusShipped: 1 references"}
+	if !reflect.DeepEqual(ctx.References, wantRefs) {
+		t.Errorf("References = %q, want %q", ctx.References, wantRefs)
+	}
+
+	// the untyped KB/MB block has no shared type
+	ctx, err = BuildGroupContext(src, identAt(t, src, "MB", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.TypeName != "" || !reflect.DeepEqual(ctx.Names, []string{"KB", "MB"}) {
+		t.Errorf("type = %q, names = %q", ctx.TypeName, ctx.Names)
+	}
+}
@@ -0,0 +1,734 @@
+package rename
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func init() {
+	RegisterProvider("heuristic", func() (Provider, error) { return HeuristicProvider{}, nil })
+}
+
+// HeuristicProvider suggests names with fixed rules applied to the gathered
+// context: the type of a variable, the call it is assigned from, the doc
+// comment of a function, common Go abbreviations. It needs no model, so it
+// works on machines without one and always gives the same answer.
+type HeuristicProvider struct{}
+
+func (HeuristicProvider) Name() string { return "heuristic" }
+
+// Generate fails: the rules read the context, not the prompt text.
+func (HeuristicProvider) Generate(prompt string) (string, error) {
+	return "", fmt.Errorf("heuristic provider needs the rename context; group renames are not supported")
+}
+
+// GenerateFromContext applies the rules for the kind of ctx and returns up
+// to three suggestions as a JSON array, the shape models are asked for.
+func (HeuristicProvider) GenerateFromContext(prompt string, ctx any) (string, error) {
+	var c *candidates
+	switch ctx := ctx.(type) {
+	case *VarContext:
+		c = newCandidates(ctx.VarName)
+		c.fromVar(ctx)
+	case *FieldContext:
+		c = newCandidates(ctx.FieldName)
+		c.fromField(ctx)
+	case *FuncContext:
+		c = newCandidates(ctx.FuncName)
+		c.fromFunc(ctx.Doc, ctx.Receiver)
+	case *InterfaceContext:
+		c = newCandidates(ctx.MethodName)
+		c.fromFunc(ctx.Doc, "")
+	case *TypeContext:
+		c = newCandidates(ctx.TypeName)
+		c.fromType(ctx)
+	case *DeclContext:
+		c = newCandidates(ctx.Name)
+		c.fromDecl(ctx)
+	case *ReceiverContext:
+		c = newCandidates(ctx.Receiver)
+		c.fromReceiver(ctx)
+	case *TypeParamContext:
+		c = newCandidates(ctx.Name)
+		c.fromTypeParam(ctx)
+	case *ImportContext:
+		c = newCandidates(ctx.Name)
+		c.fromImport(ctx)
+	default:
+		return "", fmt.Errorf("heuristic provider has no rules for %T", ctx)
+	}
+	c.fromWords()
+
+	if len(c.list) == 0 {
+		return "", fmt.Errorf("no heuristic applies to %q", c.old)
+	}
+	out, err := json.Marshal(c.list[:min(3, len(c.list))])
+	return string(out), err
+}
+
+// candidates collects suggestions in order of preference, keeping the
+// exported status of the old name.
+type candidates struct {
+	old      string
+	exported bool
+	list     []heuristicSuggestion
+}
+
+type heuristicSuggestion struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+func newCandidates(old string) *candidates {
+	return &candidates{old: old, exported: token.IsExported(old)}
+}
+
+// add suggests the name made of words, unless it is the old name, vague
+// or already suggested.
+func (c *candidates) add(words []string, reason string) {
+	if len(words) == 0 {
+		return
+	}
+	name := joinWords(words, c.exported)
+	if name == c.old || !token.IsIdentifier(name) || slices.Contains(vagueNames, strings.ToLower(name)) {
+		return
+	}
+	for _, s := range c.list {
+		if s.Name == name {
+			return
+		}
+	}
+	c.list = append(c.list, heuristicSuggestion{Name: name, Reason: reason})
+}
+
+// typeNames are the conventional names of values of well-known types.
+var typeNames = map[string][]string{
+	"error":               {"err"},
+	"bool":                {"ok"},
+	"int":                 {"n"},
+	"string":              {"s"},
+	"[]byte":              {"buf"},
+	"[]int":               {"nums"},
+	"[]string":            {"strs"},
+	"context.Context":     {"ctx"},
+	"*http.Request":       {"req"},
+	"*http.Response":      {"resp"},
+	"http.ResponseWriter": {"w"},
+	"*http.Client":        {"client"},
+	"*bytes.Buffer":       {"buf"},
+	"bytes.Buffer":        {"buf"},
+	"*strings.Builder":    {"b", "sb"},
+	"strings.Builder":     {"b", "sb"},
+	"*os.File":            {"f"},
+	"io.Reader":           {"r"},
+	"io.Writer":           {"w"},
+	"time.Duration":       {"d"},
+	"time.Time":           {"t"},
+	"*sql.DB":             {"db"},
+	"*sql.Rows":           {"rows"},
+	"*sql.Tx":             {"tx"},
+	"net.Conn":            {"conn"},
+	"sync.Mutex":          {"mu"},
+	"sync.RWMutex":        {"mu"},
+	"sync.WaitGroup":      {"wg"},
+	"*regexp.Regexp":      {"re"},
+	"*testing.T":          {"t"},
+	"*log.Logger":         {"logger"},
+}
+
+// callNames are the conventional names of what well-known functions
+// return.
+var callNames = map[string][]string{
+	"fmt.Sprintf":        {"msg", "s"},
+	"fmt.Sprint":         {"s"},
+	"fmt.Errorf":         {"err"},
+	"errors.New":         {"err"},
+	"os.Open":            {"f"},
+	"os.Create":          {"f"},
+	"os.OpenFile":        {"f"},
+	"os.ReadFile":        {"content", "b"},
+	"io.ReadAll":         {"body", "b"},
+	"json.Marshal":       {"b"},
+	"strconv.Atoi":       {"n"},
+	"strconv.Itoa":       {"s"},
+	"time.Now":           {"now", "start"},
+	"time.Since":         {"elapsed"},
+	"http.Get":           {"resp"},
+	"http.NewRequest":    {"req"},
+	"sql.Open":           {"db"},
+	"net.Dial":           {"conn"},
+	"strings.Split":      {"parts", "fields"},
+	"strings.Fields":     {"fields"},
+	"strings.TrimSpace":  {"trimmed"},
+	"filepath.Join":      {"path"},
+	"context.Background": {"ctx"},
+}
+
+// abbreviations maps words to the short forms Go code conventionally uses.
+var abbreviations = map[string]string{
+	"message":       "msg",
+	"configuration": "cfg",
+	"config":        "cfg",
+	"context":       "ctx",
+	"request":       "req",
+	"response":      "resp",
+	"buffer":        "buf",
+	"error":         "err",
+	"index":         "idx",
+	"number":        "num",
+	"amount":        "amt",
+	"percent":       "pct",
+	"percentage":    "pct",
+	"sequence":      "seq",
+	"source":        "src",
+	"destination":   "dst",
+	"directory":     "dir",
+	"argument":      "arg",
+	"arguments":     "args",
+	"parameter":     "param",
+	"parameters":    "params",
+	"database":      "db",
+	"connection":    "conn",
+	"channel":       "ch",
+	"function":      "fn",
+	"previous":      "prev",
+	"current":       "cur",
+	"maximum":       "max",
+	"minimum":       "min",
+	"address":       "addr",
+	"temporary":     "tmp",
+	"length":        "len",
+	"initialize":    "init",
+	"specification": "spec",
+	"reference":     "ref",
+	"statistics":    "stats",
+	"quantity":      "qty",
+	"customer":      "cust",
+	"description":   "desc",
+	"document":      "doc",
+}
+
+// initialisms are written in a single case, as in userID or URLPath.
+var initialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "http": true, "https": true, "json": true, "xml": true, "api": true,
+	"sql": true, "html": true, "ip": true, "tcp": true, "udp": true, "uuid": true, "utf8": true, "ascii": true,
+}
+
+// verbPrefixes are dropped from the name of a function to name what it
+// returns, as in NewOrder or loadConfig.
+var verbPrefixes = []string{"new", "get", "load", "read", "parse", "build", "make", "create", "find", "fetch", "compute", "calc", "calculate", "decode", "open"}
+
+// vagueWords are dropped from type names, as in OrderStruct or ServiceImpl.
+var vagueWords = []string{"struct", "data", "info", "obj", "object", "impl", "type"}
+
+// pathPrefixRe matches the import path before the package name in a type
+// qualified with types.RelativeTo, e.g. "net/" in "*net/http.Request".
+var pathPrefixRe = regexp.MustCompile(`[\w.-]+/`)
+
+func (c *candidates) fromVar(ctx *VarContext) {
+	typ := pathPrefixRe.ReplaceAllString(ctx.VarType, "")
+	switch ctx.Kind {
+	case "range key":
+		if typ == "int" {
+			c.add([]string{"i"}, "conventional loop index")
+		} else {
+			c.add([]string{"k"}, "conventional map key")
+			c.add([]string{"key"}, "map key")
+		}
+	case "named result":
+		if typ == "int" {
+			c.add([]string{"n"}, "conventional count result")
+		}
+	}
+
+	// only a local assigned in the function can come from one of the
+	// calls; print-like calls taking ...any yield nothing worth naming
+	if ctx.Kind == "local variable" && len(ctx.Assignments) > 0 {
+		for _, call := range ctx.Calls {
+			name, params, result := parseCallee(call)
+			if pathPrefixRe.ReplaceAllString(firstResult(result), "") != typ {
+				continue
+			}
+			if _, known := callNames[name]; known || !strings.HasSuffix(params, "...any") {
+				c.fromCall(name)
+			}
+		}
+	}
+
+	if typ == "int" || typ == "float64" {
+		for _, a := range ctx.Assignments {
+			if strings.Contains(a, "+=") {
+				c.add([]string{"total"}, "accumulates a sum")
+				c.add([]string{"sum"}, "accumulates a sum")
+				break
+			}
+		}
+	}
+	c.fromTypeName(typ, true)
+}
+
+// fromCall suggests names for the result of the function name, e.g.
+// "fmt.Sprintf" or "NewOrder".
+func (c *candidates) fromCall(name string) {
+	if names, ok := callNames[name]; ok {
+		for _, n := range names {
+			c.add([]string{n}, "conventional for "+name)
+		}
+		return
+	}
+	if !strings.Contains(name, ".") && slices.Contains([]string{"make", "new", "append", "len", "cap", "min", "max"}, name) {
+		return // the type says more than the builtin
+	}
+	words := splitWords(name[strings.LastIndex(name, ".")+1:])
+	if len(words) > 1 && slices.Contains(verbPrefixes, words[0]) {
+		words = words[1:]
+	}
+	c.add(words, "result of "+name)
+}
+
+// fromTypeName suggests names for a value of type typ. Well-known types
+// get their conventional names when short is set, and named types give
+// their own name, pluralized for slices and maps.
+func (c *candidates) fromTypeName(typ string, short bool) {
+	if names, ok := typeNames[typ]; ok {
+		if short {
+			for _, n := range names {
+				c.add([]string{n}, "conventional for "+typ)
+			}
+		}
+		return
+	}
+
+	plural := false
+	for {
+		switch {
+		case strings.HasPrefix(typ, "*"):
+			typ = typ[1:]
+			continue
+		case strings.HasPrefix(typ, "[]"):
+			typ, plural = typ[2:], true
+			continue
+		case strings.HasPrefix(typ, "map["):
+			if end := matchingBracket(typ, len("map")); end > 0 {
+				typ, plural = typ[end+1:], true
+				continue
+			}
+		case strings.HasPrefix(typ, "chan "):
+			c.add([]string{"ch"}, "conventional for channels")
+			return
+		case strings.HasPrefix(typ, "func("):
+			c.add([]string{"fn"}, "conventional for functions")
+			return
+		}
+		break
+	}
+	if i := strings.Index(typ, "["); i > 0 {
+		typ = typ[:i] // type arguments
+	}
+	typ = typ[strings.LastIndex(typ, ".")+1:]
+	if !token.IsIdentifier(typ) || predeclaredTypes[typ] {
+		return
+	}
+
+	words := dropVague(splitWords(typ))
+	if plural && len(words) > 0 {
+		words[len(words)-1] = pluralize(words[len(words)-1])
+	}
+	c.add(words, "named after its type")
+}
+
+// predeclaredTypes say nothing about a value.
+var predeclaredTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+	"string": true, "bool": true, "byte": true, "rune": true, "error": true, "any": true,
+}
+
+func (c *candidates) fromField(ctx *FieldContext) {
+	words := splitWords(ctx.FieldName)
+	owner := dropVague(splitWords(ctx.StructName))
+	if len(words) > len(owner) && slices.Equal(words[:len(owner)], owner) {
+		c.add(words[len(owner):], "struct name is implied")
+	}
+	c.fromTypeName(pathPrefixRe.ReplaceAllString(ctx.FieldType, ""), false)
+}
+
+// fromFunc suggests a verb and object taken from doc, e.g.
+// CalculateDiscount for "calculates a discount on the total", and drops
+// the Get prefix of getters and a repeated receiver type.
+func (c *candidates) fromFunc(doc, receiver string) {
+	name := splitWords(c.old)
+
+	words := strings.FieldsFunc(strings.ToLower(doc), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for len(words) > 0 && (slices.Contains([]string{"this", "function", "func", "method", "it"}, words[0]) || words[0] == strings.Join(name, "")) {
+		words = words[1:]
+	}
+	if len(words) > 0 && !slices.Contains([]string{"returns", "reports", "is", "does", "implements"}, words[0]) {
+		verb := singularVerb(words[0])
+		for _, w := range words[1:] {
+			if slices.Contains([]string{"a", "an", "the", "its", "their", "all", "each", "every", "of", "and", "for", "to"}, w) {
+				continue
+			}
+			c.add([]string{verb, w}, "from its doc comment")
+			break
+		}
+	}
+
+	if len(name) > 1 && name[0] == "get" {
+		c.add(name[1:], "Go getters omit Get")
+	}
+	if receiver != "" {
+		typ := splitWords(strings.TrimLeft(pathPrefixRe.ReplaceAllString(receiver, ""), "*"))
+		for _, prefix := range [][]string{typ, dropVague(typ)} {
+			if len(prefix) > 0 && len(name) > len(prefix) && slices.Equal(name[:len(prefix)], prefix) {
+				c.add(name[len(prefix):], "receiver type is implied")
+			}
+		}
+	}
+}
+
+func (c *candidates) fromType(ctx *TypeContext) {
+	words := splitWords(ctx.TypeName)
+	if trimmed := dropVague(words); len(trimmed) < len(words) {
+		words = trimmed
+		c.add(words, "drops a redundant suffix")
+	}
+	if ctx.Interface {
+		if len(words) > 1 && words[0] == "i" {
+			c.add(words[1:], "Go interfaces have no I prefix")
+		}
+		if words[len(words)-1] == "interface" && len(words) > 1 {
+			c.add(words[:len(words)-1], "drops a redundant suffix")
+		}
+		if len(ctx.Methods) == 1 {
+			method := ctx.Methods[0][:strings.IndexAny(ctx.Methods[0]+"(", "(")]
+			if er := agentNoun(method); er != "" {
+				c.add(splitWords(er), "one-method interface convention")
+			}
+		}
+	}
+	c.dropPackageName(words, ctx.PackageName)
+}
+
+func (c *candidates) fromDecl(ctx *DeclContext) {
+	if msg, ok := errorMessage(ctx.Value); ok {
+		words := strings.FieldsFunc(strings.ToLower(msg), func(r rune) bool { return !unicode.IsLetter(r) })
+		c.add(append([]string{"err"}, words[:min(3, len(words))]...), "named after its message")
+	}
+	words := splitWords(c.old)
+	if strings.Contains(c.old, "_") {
+		c.add(words, "Go uses MixedCaps")
+	}
+	if trimmed := dropVague(words); len(trimmed) < len(words) {
+		c.add(trimmed, "drops a redundant suffix")
+	}
+	c.dropPackageName(words, ctx.PackageName)
+	if ctx.Kind == "variable" {
+		c.fromTypeName(pathPrefixRe.ReplaceAllString(ctx.Type, ""), false)
+	}
+}
+
+// dropPackageName suggests words without a leading package name, which
+// callers already write, as in order.OrderItem.
+func (c *candidates) dropPackageName(words []string, pkg string) {
+	if c.exported && len(words) > 1 && words[0] == pkg {
+		c.add(words[1:], "package name is implied")
+	}
+}
+
+func (c *candidates) fromReceiver(ctx *ReceiverContext) {
+	// the name most other methods already use keeps them consistent
+	best, most := "", 0
+	for _, cur := range ctx.Current {
+		name, count, _ := strings.Cut(cur, ": ")
+		n, _ := strconv.Atoi(strings.Fields(count)[0])
+		if name != ctx.Receiver && n > most {
+			best, most = name, n
+		}
+	}
+	if best != "" {
+		c.add([]string{best}, "used by the other methods")
+	}
+
+	words := dropVague(splitWords(ctx.TypeName))
+	if len(words) == 0 {
+		return
+	}
+	var initials strings.Builder
+	for _, w := range words {
+		initials.WriteByte(w[0])
+	}
+	c.add([]string{initials.String()}, "abbreviates "+ctx.TypeName)
+	c.add([]string{words[0][:1]}, "abbreviates "+ctx.TypeName)
+}
+
+func (c *candidates) fromTypeParam(ctx *TypeParamContext) {
+	for _, use := range ctx.Signature {
+		switch {
+		case strings.Contains(use, "map["+ctx.Name+"]"):
+			c.add([]string{"k"}, "conventional for map keys")
+		case strings.Contains(use, "[]"+ctx.Name):
+			c.add([]string{"e"}, "conventional for elements")
+		}
+	}
+	switch constraint := ctx.Constraint; {
+	case constraint == "comparable":
+		c.add([]string{"k"}, "conventional for comparable keys")
+	case token.IsIdentifier(constraint) && constraint != "any":
+		c.add([]string{strings.ToLower(constraint[:1])}, "initial of "+constraint)
+	}
+	c.add([]string{"t"}, "conventional type parameter")
+}
+
+func (c *candidates) fromImport(ctx *ImportContext) {
+	if ctx.Aliased && ctx.Name != ctx.Default {
+		c.add([]string{ctx.Default}, "the package's own name")
+	}
+	elems := strings.Split(ctx.Path, "/")
+	if n := len(elems); n > 1 && strings.HasPrefix(elems[n-1], "v") && strings.TrimLeft(elems[n-1][1:], "0123456789") == "" {
+		elems = elems[:n-1] // major version suffix
+	}
+	if n := len(elems); n > 1 {
+		parent := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, elems[n-2])
+		if parent != "" {
+			c.add([]string{parent[:1] + ctx.Default}, "qualified by "+elems[n-2])
+			c.add([]string{parent + ctx.Default}, "qualified by "+elems[n-2])
+		}
+	}
+}
+
+// fromWords suggests the old name with its words abbreviated the way Go
+// code usually writes them, or else spelled out. Exported names are part
+// of an API read by callers without the surrounding code, so they are
+// only spelled out.
+func (c *candidates) fromWords() {
+	words := splitWords(c.old)
+	short := slices.Clone(words)
+	long := slices.Clone(words)
+	for i, w := range words {
+		if abbr, ok := abbreviations[w]; ok {
+			short[i] = abbr
+		}
+		for full, abbr := range abbreviations {
+			if abbr == w && (long[i] == w || len(full) < len(long[i])) {
+				long[i] = full
+			}
+		}
+	}
+	if !c.exported {
+		c.add(short, "common Go abbreviation")
+	}
+	c.add(long, "spells out the abbreviation")
+}
+
+// parseCallee splits a calleeString, e.g.
+// "func fmt.Sprintf(format string, a ...any) string", into the qualified
+// name of the function, its parameter list and its result.
+func parseCallee(s string) (name, params, result string) {
+	s = strings.TrimPrefix(s, "func ")
+	if strings.HasPrefix(s, "(") { // method: func (*T).M(...)
+		end := matchingBracket(s, 0)
+		if end < 0 {
+			return "", "", ""
+		}
+		s = strings.TrimPrefix(s[end+1:], ".")
+	}
+	i := strings.IndexAny(s, "([")
+	if i < 0 {
+		return s, "", ""
+	}
+	name = pathPrefixRe.ReplaceAllString(s[:i], "")
+	rest := s[i:]
+	if rest[0] == '[' { // type parameters
+		end := matchingBracket(rest, 0)
+		if end < 0 {
+			return name, "", ""
+		}
+		rest = rest[end+1:]
+	}
+	if end := matchingBracket(rest, 0); end >= 0 {
+		params, result = rest[1:end], strings.TrimSpace(rest[end+1:])
+	}
+	return name, params, result
+}
+
+// firstResult returns the type of the first result of a function with
+// results, e.g. "*Config" for "(cfg *Config, err error)".
+func firstResult(results string) string {
+	if !strings.HasPrefix(results, "(") {
+		return results
+	}
+	list := results[1 : len(results)-1]
+	end, depth := len(list), 0
+	for i := 0; i < len(list) && end == len(list); i++ {
+		switch list[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	first := list[:end]
+	// a named result: "cfg *Config"
+	if name, typ, ok := strings.Cut(first, " "); ok && token.IsIdentifier(name) && !slices.Contains([]string{"func", "chan", "map", "struct", "interface"}, name) {
+		return typ
+	}
+	return first
+}
+
+// matchingBracket returns the index of the bracket closing the one at
+// s[open], or -1.
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// errorMessage returns the message of an errors.New or fmt.Errorf call.
+func errorMessage(value string) (string, bool) {
+	for _, prefix := range []string{"errors.New(", "fmt.Errorf("} {
+		rest, ok := strings.CutPrefix(value, prefix)
+		if end := strings.LastIndex(rest, ")"); ok && end >= 0 {
+			if msg, err := strconv.Unquote(rest[:end]); err == nil {
+				return msg, true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitWords splits a MixedCaps or snake_case name into lower-case words,
+// keeping initialisms whole: "HTTPServer" gives "http" and "server".
+func splitWords(name string) []string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' }) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := !unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i])
+			initialismEnd := unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || initialismEnd {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// joinWords joins lower-case words into a MixedCaps name, exported or not.
+func joinWords(words []string, exported bool) string {
+	var b strings.Builder
+	for i, w := range words {
+		switch {
+		case w == "":
+		case i == 0 && !exported:
+			b.WriteString(w)
+		case initialisms[w]:
+			b.WriteString(strings.ToUpper(w))
+		default:
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
+
+// dropVague returns words without those in vagueWords, unless nothing
+// would be left.
+func dropVague(words []string) []string {
+	kept := slices.DeleteFunc(slices.Clone(words), func(w string) bool { return slices.Contains(vagueWords, w) })
+	if len(kept) == 0 {
+		return words
+	}
+	return kept
+}
+
+// pluralize returns the plural of the English noun w.
+func pluralize(w string) string {
+	switch {
+	case strings.HasSuffix(w, "s"), strings.HasSuffix(w, "x"), strings.HasSuffix(w, "ch"), strings.HasSuffix(w, "sh"):
+		return w + "es"
+	case len(w) > 1 && strings.HasSuffix(w, "y") && !strings.ContainsRune("aeiou", rune(w[len(w)-2])):
+		return w[:len(w)-1] + "ies"
+	}
+	return w + "s"
+}
+
+// singularVerb turns the third-person verb of a doc comment into the
+// imperative a function name uses: "calculates" gives "calculate".
+func singularVerb(w string) string {
+	switch {
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// agentNoun returns the -er name of a one-method interface, e.g. Reader
+// for Read, Closer for Close or Getter for Get, or "" if the method does
+// not take one.
+func agentNoun(method string) string {
+	words := splitWords(method)
+	word := words[len(words)-1]
+	n := len(word)
+	switch last := word[n-1]; {
+	case last == 'e':
+		return method + "r"
+	case strings.ContainsRune("aiou", rune(last)):
+		return ""
+	case n >= 2 && isVowel(word[n-2]) && !strings.ContainsRune("wxy", rune(last)) && (n == 2 || !isVowel(word[n-3])):
+		// a single vowel before the final consonant doubles it in one
+		// syllable verbs, Set and Run; in longer ones it depends on the
+		// stress, Formatter but Visitor, so those are left out
+		if vowelGroups(word) > 1 {
+			return ""
+		}
+		return method + string(last) + "er"
+	}
+	return method + "er"
+}
+
+func isVowel(c byte) bool { return strings.IndexByte("aeiou", c) >= 0 }
+
+// vowelGroups counts the runs of vowels in a lower-case word, roughly its
+// syllables.
+func vowelGroups(word string) int {
+	n := 0
+	for i := range len(word) {
+		if isVowel(word[i]) && (i == 0 || !isVowel(word[i-1])) {
+			n++
+		}
+	}
+	return n
+}
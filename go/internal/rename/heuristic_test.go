@@ -0,0 +1,194 @@
+package rename
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestHeuristicProviderRun(t *testing.T) {
+	tests := []struct {
+		file     string
+		row, col int
+		want     []string
+	}{
+		{"sample.go", 9, 18, []string{"nums"}},                 // data []int
+		{"sample.go", 10, 1, []string{"total", "sum", "n"}},    // count += v
+		{"order.go", 6, 5, []string{"Order"}},                  // OrderStruct
+		{"order.go", 14, 22, []string{"CalculateDiscount"}},    // from the doc comment
+		{"order.go", 15, 1, []string{"amount"}},                // amt
+		{"order.go", 22, 1, []string{"s", "message"}},          // msg := fmt.Sprintf(...)
+		{"fibonacci.go", 20, 1, []string{"fibonacci", "nums"}}, // series := Fibonacci(10)
+	}
+	for _, tt := range tests {
+		result, err := Run("../../testdata/"+tt.file, Selector{Kind: "position", Row: tt.row, Col: tt.col}, HeuristicProvider{}, Options{})
+		if err != nil {
+			t.Errorf("%s %d:%d: %v", tt.file, tt.row, tt.col, err)
+			continue
+		}
+		var got []string
+		for _, s := range result.Suggestions {
+			got = append(got, s.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s %d:%d: suggestions = %q, want %q", tt.file, tt.row, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestHeuristicProviderContexts(t *testing.T) {
+	tests := []struct {
+		ctx  any
+		want string
+	}{
+		{&VarContext{VarName: "x", VarType: "*net/http.Request"}, "req"},
+		{&VarContext{VarName: "x", VarType: "[]*Order"}, "orders"},
+		{&VarContext{VarName: "x", VarType: "map[string]Entry", Calls: []string{"func NewEntry() Entry"}}, "entries"},
+		{&VarContext{VarName: "x", VarType: "*Config", Kind: "local variable", Assignments: []string{":= x"}, Calls: []string{"func loadConfig(path string) (*Config, error)", "func (*Config).Validate() error"}}, "config"},
+		{&VarContext{VarName: "x", VarType: "string", Kind: "local variable", Assignments: []string{":= x"}, Calls: []string{"func fmt.Sprintf(format string, a ...any) string"}}, "msg"},
+		{&VarContext{VarName: "x", VarType: "int", Kind: "local variable", Assignments: []string{":= x"}, Calls: []string{"func fmt.Println(a ...any) (n int, err error)"}}, "n"},
+		{&VarContext{VarName: "k", VarType: "string", Kind: "range key"}, "key"},
+		{&FieldContext{FieldName: "UserName", StructName: "User", FieldType: "string"}, "Name"},
+		{&FuncContext{FuncName: "GetName", Receiver: "*User"}, "Name"},
+		{&FuncContext{FuncName: "UserName", Receiver: "*UserInfo"}, "Name"},
+		{&TypeContext{TypeName: "IStore", Interface: true, PackageName: "p"}, "Store"},
+		{&TypeContext{TypeName: "Thing", Interface: true, Methods: []string{"Close() error"}}, "Closer"},
+		{&DeclContext{Name: "ErrX", Kind: "variable", Value: `errors.New("user not found")`}, "ErrUserNotFound"},
+		{&DeclContext{Name: "MAX_RETRIES", Kind: "constant"}, "MaxRetries"},
+		{&DeclContext{Name: "OrderLimit", Kind: "constant", PackageName: "order"}, "Limit"},
+		{&ReceiverContext{Receiver: "this", TypeName: "OrderItem", Current: []string{"oi: 3 methods", "this: 1 methods"}}, "oi"},
+		{&TypeParamContext{Name: "X", Constraint: "comparable"}, "K"},
+		{&TypeParamContext{Name: "X", Constraint: "any", Signature: []string{"param s []X"}}, "E"},
+		{&ImportContext{Name: "rand2", Path: "crypto/rand", Default: "rand", Aliased: true}, "rand, crand, cryptorand"},
+	}
+	for _, tt := range tests {
+		raw, err := HeuristicProvider{}.GenerateFromContext("", tt.ctx)
+		if err != nil {
+			t.Errorf("%+v: %v", tt.ctx, err)
+			continue
+		}
+		suggestions, err := parseSuggestions(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range suggestions {
+			names = append(names, s.Name)
+		}
+		if !strings.Contains(", "+strings.Join(names, ", ")+",", ", "+tt.want+",") {
+			t.Errorf("%+v: suggestions = %q, want %s", tt.ctx, names, tt.want)
+		}
+	}
+}
+
+func TestHeuristicProviderAbbreviatesUnexportedNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		want, skip string
+	}{
+		{"maximumQty", "maxQty", ""},
+		{"MaximumQty", "MaximumQuantity", "MaxQty"},
+	}
+	for _, tt := range tests {
+		raw, err := HeuristicProvider{}.GenerateFromContext("", &DeclContext{Name: tt.name, Kind: "constant"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		suggestions, err := parseSuggestions(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range suggestions {
+			names = append(names, s.Name)
+		}
+		if !slices.Contains(names, tt.want) {
+			t.Errorf("%s: suggestions = %q, want %s", tt.name, names, tt.want)
+		}
+		if tt.skip != "" && slices.Contains(names, tt.skip) {
+			t.Errorf("%s: suggestions = %q, do not want %s", tt.name, names, tt.skip)
+		}
+	}
+}
+
+func TestHeuristicProviderNeedsContext(t *testing.T) {
+	if _, err := CallLLM("rename x", HeuristicProvider{}); err == nil {
+		t.Error("CallLLM with a bare prompt succeeded")
+	}
+}
+
+func TestAgentNoun(t *testing.T) {
+	tests := []struct {
+		method, want string
+	}{
+		{"Read", "Reader"},
+		{"Close", "Closer"},
+		{"Flush", "Flusher"},
+		{"Fix", "Fixer"},
+		{"Get", "Getter"},
+		{"Set", "Setter"},
+		{"Run", "Runner"},
+		{"Scan", "Scanner"},
+		{"Format", ""}, // Formatter, but Visitor for Visit
+		{"Visit", ""},
+		{"Do", ""},
+	}
+	for _, tt := range tests {
+		if got := agentNoun(tt.method); got != tt.want {
+			t.Errorf("agentNoun(%q) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"OrderStruct", []string{"order", "struct"}},
+		{"HTTPServer", []string{"http", "server"}},
+		{"userID", []string{"user", "id"}},
+		{"MAX_SIZE", []string{"max", "size"}},
+		{"x", []string{"x"}},
+	}
+	for _, tt := range tests {
+		if got := splitWords(tt.name); !slices.Equal(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got := joinWords(splitWords(tt.name), false); tt.name == "userID" && got != tt.name {
+			t.Errorf("joinWords(splitWords(%q)) = %q", tt.name, got)
+		}
+	}
+}
+
+func TestParseCallee(t *testing.T) {
+	tests := []struct {
+		callee, name, params, result string
+	}{
+		{"func fmt.Sprintf(format string, a ...any) string", "fmt.Sprintf", "format string, a ...any", "string"},
+		{"func make([]int, int) []int", "make", "[]int, int", "[]int"},
+		{"func (*OrderStruct).Total() float64", "Total", "", "float64"},
+		{"func Map[T, U any](s []T, f func(T) U) []U", "Map", "s []T, f func(T) U", "[]U"},
+		{"func net/http.Get(url string) (resp *net/http.Response, err error)", "http.Get", "url string", "(resp *net/http.Response, err error)"},
+	}
+	for _, tt := range tests {
+		if name, params, result := parseCallee(tt.callee); name != tt.name || params != tt.params || result != tt.result {
+			t.Errorf("parseCallee(%q) = %q, %q, %q", tt.callee, name, params, result)
+		}
+	}
+}
+
+func TestFirstResult(t *testing.T) {
+	tests := []struct{ results, want string }{
+		{"string", "string"},
+		{"(*Config, error)", "*Config"},
+		{"(resp *net/http.Response, err error)", "*net/http.Response"},
+		{"(map[string]int, error)", "map[string]int"},
+		{"(func(a, b int) int, bool)", "func(a, b int) int"},
+	}
+	for _, tt := range tests {
+		if got := firstResult(tt.results); got != tt.want {
+			t.Errorf("firstResult(%q) = %q, want %q", tt.results, got, tt.want)
+		}
+	}
+}
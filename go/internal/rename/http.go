@@ -0,0 +1,74 @@
+package rename
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout bounds a single request to an HTTP-backed provider.
+const defaultHTTPTimeout = 2 * time.Minute
+
+// httpReply is the part of an HTTP response the providers care about.
+type httpReply struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// postJSON marshals body, POSTs it to url and returns the response. Non-2xx
+// responses are not treated as errors here; each provider decodes its own
+// error shape.
+func postJSON(client *http.Client, url string, header http.Header, body any) (*httpReply, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &httpReply{Status: resp.StatusCode, Header: resp.Header, Body: data}, nil
+}
+
+// normalizeBaseURL adds a scheme to bare host:port values and drops any
+// trailing slash so paths can be appended directly.
+func normalizeBaseURL(s string) string {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	return strings.TrimRight(s, "/")
+}
+
+// statusError formats a non-2xx response, preferring the API's own message.
+func statusError(provider string, status int, message string, body []byte) error {
+	if message == "" {
+		message = strings.TrimSpace(string(body))
+	}
+	return fmt.Errorf("%s: HTTP %d: %s", provider, status, message)
+}
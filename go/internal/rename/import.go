@@ -0,0 +1,148 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ImportContext holds context for the local name of an import to be
+// renamed
+type ImportContext struct {
+	PackageName string
+	Filename    string
+	Name        string // current local name, alias or package name
+	Path        string // import path
+	Default     string // name the imported package declares
+	Aliased     bool
+
+	Members []string // "Println: 3 uses", the members referenced through Name
+	Imports []string // the other imports of the file, "crand crypto/rand"
+	Scope   []string // package-level names an alias would collide with
+
+	pkgName *types.PkgName
+	spec    *ast.ImportSpec
+}
+
+// BuildImportContext builds context for the import ident declares or
+// qualifies, e.g. "fmt" in fmt.Println.
+func BuildImportContext(src *Source, ident *ast.Ident) (*ImportContext, error) {
+	pn, ok := src.Info.ObjectOf(ident).(*types.PkgName)
+	if !ok {
+		return nil, fmt.Errorf("%q is not an import", ident.Name)
+	}
+	file := src.fileOf(pn.Pos())
+	if file == nil {
+		return nil, fmt.Errorf("declaration of %q is outside the loaded package", ident.Name)
+	}
+
+	var spec *ast.ImportSpec
+	for _, imp := range file.Imports {
+		if src.Info.Defs[imp.Name] == pn || src.Info.Implicits[imp] == pn {
+			spec = imp
+			break
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("import of %q not found", ident.Name)
+	}
+	if spec.Name != nil && (spec.Name.Name == "." || spec.Name.Name == "_") {
+		return nil, fmt.Errorf("%q imports cannot be renamed", spec.Name.Name)
+	}
+
+	ctx := &ImportContext{
+		PackageName: src.Pkg.Name(),
+		Filename:    src.Fset.Position(file.Pos()).Filename,
+		Name:        pn.Name(),
+		Path:        pn.Imported().Path(),
+		Default:     pn.Imported().Name(),
+		Aliased:     spec.Name != nil,
+		pkgName:     pn,
+		spec:        spec,
+	}
+
+	uses := map[string]int{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && src.Info.Uses[x] == pn {
+				uses[sel.Sel.Name]++
+			}
+		}
+		return true
+	})
+	for _, m := range slices.Sorted(maps.Keys(uses)) {
+		ctx.Members = append(ctx.Members, fmt.Sprintf("%s: %d uses", m, uses[m]))
+	}
+
+	for _, imp := range file.Imports {
+		if imp == spec {
+			continue
+		}
+		entry := trimQuotes(imp.Path.Value)
+		if imp.Name != nil {
+			entry = imp.Name.Name + " " + entry
+		}
+		ctx.Imports = append(ctx.Imports, entry)
+	}
+	ctx.Scope = src.Pkg.Scope().Names()
+
+	return ctx, nil
+}
+
+// importIdent returns an identifier naming the import of spec: its alias,
+// or else the first reference qualified by it, so that the import can be
+// renamed from its path by adding an alias.
+func (s *Source) importIdent(spec *ast.ImportSpec) (*ast.Ident, error) {
+	if spec.Name != nil {
+		return spec.Name, nil
+	}
+	pn, ok := s.Info.Implicits[spec].(*types.PkgName)
+	if !ok {
+		return nil, fmt.Errorf("cannot resolve import %s", spec.Path.Value)
+	}
+	var found *ast.Ident
+	ast.Inspect(s.fileOf(spec.Pos()), func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && s.Info.Uses[id] == pn {
+			found = id
+		}
+		return found == nil
+	})
+	if found == nil {
+		return nil, fmt.Errorf("import %s is not used, so it has no name to rename", spec.Path.Value)
+	}
+	return found, nil
+}
+
+// validateImport reports why name cannot replace the local name of the
+// import in ctx, or nil if it can.
+func validateImport(src *Source, ctx *ImportContext, name string) error {
+	if err := validateName(ctx.Name, name, false, objectScope(src, ctx.pkgName)); err != nil {
+		return err
+	}
+	if name != strings.ToLower(name) || strings.Contains(name, "_") {
+		return fmt.Errorf("import names are lower case without underscores")
+	}
+	return nil
+}
+
+// edits renames the import and every reference qualified by it in its
+// file, adding an alias to the import spec if it has none.
+func (ctx *ImportContext) edits(src *Source, name string) []Edit {
+	edits := src.identEdits(src.refs(ctx.pkgName, src.fileOf(ctx.pkgName.Pos())), name)
+	if ctx.spec.Name == nil {
+		p := src.Fset.Position(ctx.spec.Path.Pos())
+		alias := Edit{
+			Filename: p.Filename,
+			Offset:   p.Offset,
+			Line:     p.Line,
+			Column:   p.Column,
+			NewText:  name + " ",
+		}
+		// the spec precedes every reference
+		edits = append([]Edit{alias}, edits...)
+	}
+	return edits
+}
@@ -0,0 +1,108 @@
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const importSrc = `package p
+
+import (
+	"fmt"
+	str "strings"
+)
+
+var Upper = str.ToUpper
+
+func greet(name string) string {
+	return fmt.Sprintf("hi %s", str.TrimSpace(name)) + fmt.Sprint()
+}
+`
+
+func TestBuildImportContext(t *testing.T) {
+	src := writeSource(t, importSrc)
+	ctx, err := BuildImportContext(src, identAt(t, src, "str", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Name != "str" || ctx.Path != "strings" || ctx.Default != "strings" || !ctx.Aliased {
+		t.Errorf("ctx = %+v", ctx)
+	}
+	if strings.Join(ctx.Members, ", ") != "ToUpper: 1 uses, TrimSpace: 1 uses" {
+		t.Errorf("Members = %q", ctx.Members)
+	}
+	if strings.Join(ctx.Imports, ", ") != "fmt" {
+		t.Errorf("Imports = %q", ctx.Imports)
+	}
+
+	tests := []struct {
+		name, want string
+	}{
+		{"strs", ""},
+		{"name", `collides with parameter "name"`},
+		{"greet", `collides with package-level func "greet"`},
+		{"fmt", `collides with import "fmt"`},
+		{"str_util", "lower case without underscores"},
+	}
+	for _, tt := range tests {
+		err := validateImport(src, ctx, tt.name)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateImport(%q) = %v, want nil", tt.name, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("validateImport(%q) = %v, want error containing %q", tt.name, err, tt.want)
+		}
+	}
+
+	if edits := ctx.edits(src, "strs"); len(edits) != 3 {
+		t.Errorf("edits = %+v", edits)
+	}
+}
+
+func TestRunAddsImportAlias(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "src.go")
+	if err := os.WriteFile(path, []byte(importSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{replies: []string{`[{"name":"f","reason":"short"}]`}}
+
+	// cursor on the fmt qualifier of fmt.Sprintf
+	result, err := Run(path, Selector{Kind: "position", Row: 11, Col: 8}, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edits := result.Suggestions[0].Edits
+	if len(edits) != 3 {
+		t.Fatalf("edits = %+v", edits)
+	}
+	if e := edits[0]; e.Line != 4 || e.OldText != "" || e.NewText != "f " {
+		t.Errorf("alias edit = %+v", e)
+	}
+	if e := edits[2]; e.Line != 11 || e.OldText != "fmt" || e.NewText != "f" {
+		t.Errorf("last edit = %+v", e)
+	}
+}
+
+func TestRenameImportFromItsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "src.go")
+	if err := os.WriteFile(path, []byte(importSrc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// cursor on "fmt" in the import block
+	result, err := Rename(path, Selector{Kind: "position", Row: 4, Col: 2}, "f", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edits := result.Suggestions[0].Edits
+	if len(edits) != 3 || edits[0].Line != 4 || edits[0].NewText != "f " {
+		t.Errorf("edits = %+v", edits)
+	}
+
+	src := writeSource(t, "package p\n\nimport \"fmt\"\n")
+	_, err = ResolveSelector(src, Selector{Kind: "position", Row: 3, Col: 9})
+	if err == nil || !strings.Contains(err.Error(), "not used") {
+		t.Errorf("unused import: err = %v", err)
+	}
+}
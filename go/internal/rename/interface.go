@@ -0,0 +1,158 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+)
+
+// InterfaceContext holds context for an interface method to be renamed
+// together with the methods implementing it
+type InterfaceContext struct {
+	PackageName   string
+	InterfaceName string
+	InterfaceDoc  string
+	MethodName    string
+	Signature     string // e.g. func(w io.Writer) error
+	Doc           string
+
+	Methods         []string // every method of the interface
+	Implementations []string // "*OrderStruct.ApplyDiscount (file:line:col)"
+	CallSites       []string // "caller: call (file:line:col)"
+
+	// Warnings explains what renaming could break that the edits cannot
+	// fix, such as implementations outside the loaded packages.
+	Warnings []string
+
+	method *types.Func
+	impls  []*types.Func // implementing methods declared in the loaded files
+}
+
+// BuildInterfaceContext builds context for the interface method ident
+// refers to.
+func BuildInterfaceContext(src *Source, ident *ast.Ident) (*InterfaceContext, error) {
+	fn, ok := src.Info.ObjectOf(ident).(*types.Func)
+	if !ok || !isInterfaceMethod(fn) {
+		return nil, fmt.Errorf("%q is not an interface method", ident.Name)
+	}
+	named, ok := fn.Type().(*types.Signature).Recv().Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%q is a method of an unnamed interface", ident.Name)
+	}
+	iface := named.Underlying().(*types.Interface)
+	if src.fileOf(fn.Pos()) == nil {
+		return nil, fmt.Errorf("declaration of %q is outside the loaded package", ident.Name)
+	}
+
+	ctx := &InterfaceContext{
+		PackageName:   fn.Pkg().Name(),
+		InterfaceName: named.Obj().Name(),
+		MethodName:    fn.Name(),
+		Signature:     src.typeString(fn.Type()),
+		Methods:       src.methodSet(named),
+		CallSites:     src.callers(fn),
+		method:        fn,
+	}
+	if ts := src.typeSpecOf(named.Obj()); ts != nil {
+		ctx.InterfaceDoc = typeDoc(src.pathTo(ts.Pos()), ts)
+	}
+	for _, n := range src.pathTo(fn.Pos()) {
+		if field, ok := n.(*ast.Field); ok {
+			if field.Doc != nil {
+				ctx.Doc = strings.TrimSpace(field.Doc.Text())
+			}
+			break
+		}
+	}
+
+	for _, t := range src.implementations(iface) {
+		obj, _, _ := types.LookupFieldOrMethod(t, true, fn.Pkg(), fn.Name())
+		m, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+		entry := fmt.Sprintf("%s.%s (%s)", src.typeString(t), m.Name(), src.position(m.Pos()))
+		ctx.Implementations = append(ctx.Implementations, entry)
+		if src.fileOf(m.Pos()) == nil {
+			ctx.Warnings = append(ctx.Warnings, fmt.Sprintf("%s.%s is declared outside the loaded packages and cannot be renamed with it", src.typeString(t), m.Name()))
+			continue
+		}
+		if slices.Contains(ctx.impls, m) {
+			continue
+		}
+		ctx.impls = append(ctx.impls, m)
+		for _, other := range src.implementedBy(m) {
+			if other != types.TypeString(named, src.qualifier()) {
+				ctx.Warnings = appendNew(ctx.Warnings, fmt.Sprintf("%s.%s also satisfies %s, which renaming it breaks", src.typeString(t), m.Name(), other))
+			}
+		}
+	}
+	if named.Obj().Exported() && fn.Exported() {
+		ctx.Warnings = append(ctx.Warnings, fmt.Sprintf("%s is exported: implementations of %s outside the loaded packages will no longer satisfy it", ctx.InterfaceName, fn.Name()))
+	}
+
+	return ctx, nil
+}
+
+// isInterfaceMethod reports whether fn is declared by an interface.
+func isInterfaceMethod(fn *types.Func) bool {
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && types.IsInterface(recv.Type())
+}
+
+// implementations returns the named, non-interface types declared in the
+// loaded files that implement iface, each as T or, if only its pointer
+// does, *T.
+func (s *Source) implementations(iface *types.Interface) []types.Type {
+	if iface.NumMethods() == 0 {
+		return nil
+	}
+	var out []types.Type
+	for _, f := range s.AllFiles() {
+		ast.Inspect(f, func(n ast.Node) bool {
+			ts, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			tn, ok := s.Info.Defs[ts.Name].(*types.TypeName)
+			if !ok || tn.IsAlias() || ts.TypeParams != nil || types.IsInterface(tn.Type()) {
+				return false
+			}
+			switch t := tn.Type(); {
+			case types.Implements(t, iface):
+				out = append(out, t)
+			case types.Implements(types.NewPointer(t), iface):
+				out = append(out, types.NewPointer(t))
+			}
+			return false
+		})
+	}
+	return out
+}
+
+// validateInterfaceMethod reports why name cannot replace the method in
+// ctx and every implementation of it, or nil if it can.
+func validateInterfaceMethod(src *Source, ctx *InterfaceContext, name string) error {
+	recv := ctx.method.Type().(*types.Signature).Recv().Type()
+	if err := validateMember(src, ctx.method, name, memberScope(recv)); err != nil {
+		return err
+	}
+	for _, m := range ctx.impls {
+		if err := validateMember(src, m, name, methodScope(m)); err != nil {
+			return fmt.Errorf("in %s: %v", src.typeString(m.Type().(*types.Signature).Recv().Type()), err)
+		}
+	}
+	return nil
+}
+
+// edits renames the interface method, its implementations and every call
+// of either.
+func (ctx *InterfaceContext) edits(src *Source, name string) []Edit {
+	ids := src.refs(ctx.method, src.AllFiles()...)
+	for _, m := range ctx.impls {
+		ids = append(ids, src.refs(m, src.AllFiles()...)...)
+	}
+	return src.identEdits(ids, name)
+}
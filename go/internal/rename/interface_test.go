@@ -0,0 +1,92 @@
+package rename
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const shapeSrc = `package p
+
+import "fmt"
+
+// Shape is a closed 2D figure.
+type Shape interface {
+	// Area returns the surface in square units.
+	Area() float64
+	Perimeter() float64
+}
+
+type Square struct{ side float64 }
+
+func (s Square) Area() float64      { return s.side * s.side }
+func (s Square) Perimeter() float64 { return 4 * s.side }
+
+type Circle struct{ r float64 }
+
+func (c *Circle) Area() float64      { return 3.14 * c.r * c.r }
+func (c *Circle) Perimeter() float64 { return 2 * 3.14 * c.r }
+func (c *Circle) Radius() float64    { return c.r }
+
+func describe(s Shape) string { return fmt.Sprint(s.Area()) }
+
+func total(sq Square) float64 { return sq.Area() }
+`
+
+func TestBuildInterfaceContext(t *testing.T) {
+	src := writeSource(t, shapeSrc)
+	ctx, err := BuildInterfaceContext(src, identAt(t, src, "Area", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ctx.InterfaceName != "Shape" || ctx.Doc != "Area returns the surface in square units." {
+		t.Errorf("InterfaceName = %q, Doc = %q", ctx.InterfaceName, ctx.Doc)
+	}
+	if len(ctx.Implementations) != 2 ||
+		!strings.HasPrefix(ctx.Implementations[0], "Square.Area (") ||
+		!strings.HasPrefix(ctx.Implementations[1], "*Circle.Area (") {
+		t.Errorf("Implementations = %q", ctx.Implementations)
+	}
+	if len(ctx.CallSites) != 1 || !strings.HasPrefix(ctx.CallSites[0], "describe: s.Area()") {
+		t.Errorf("CallSites = %q", ctx.CallSites)
+	}
+	if len(ctx.Warnings) != 1 || !strings.Contains(ctx.Warnings[0], "outside the loaded packages") {
+		t.Errorf("Warnings = %q", ctx.Warnings)
+	}
+
+	if err := validateInterfaceMethod(src, ctx, "Perimeter"); err == nil || !strings.Contains(err.Error(), `collides with method "Perimeter"`) {
+		t.Errorf(`validateInterfaceMethod("Perimeter") = %v`, err)
+	}
+	if err := validateInterfaceMethod(src, ctx, "Radius"); err == nil || !strings.Contains(err.Error(), `in *Circle: collides with method "Radius"`) {
+		t.Errorf(`validateInterfaceMethod("Radius") = %v`, err)
+	}
+	if err := validateInterfaceMethod(src, ctx, "Surface"); err != nil {
+		t.Errorf(`validateInterfaceMethod("Surface") = %v`, err)
+	}
+
+	// the interface method, both implementations and both calls
+	var lines []int
+	for _, e := range ctx.edits(src, "Surface") {
+		lines = append(lines, e.Line)
+	}
+	if want := []int{8, 14, 19, 23, 25}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("edited lines = %v, want %v", lines, want)
+	}
+}
+
+func TestBuildTypeContextInterface(t *testing.T) {
+	src := writeSource(t, shapeSrc)
+	ctx := buildTypeContext(src, src.typeSpecOf(src.Pkg.Scope().Lookup("Shape").(*types.TypeName)))
+
+	if !ctx.Interface || ctx.StructDoc != "Shape is a closed 2D figure." {
+		t.Errorf("Interface = %v, StructDoc = %q", ctx.Interface, ctx.StructDoc)
+	}
+	if want := []string{"Area() float64", "Perimeter() float64"}; !reflect.DeepEqual(ctx.Methods, want) {
+		t.Errorf("Methods = %q, want %q", ctx.Methods, want)
+	}
+	if want := []string{"Square", "*Circle"}; !reflect.DeepEqual(ctx.Implementations, want) {
+		t.Errorf("Implementations = %q, want %q", ctx.Implementations, want)
+	}
+}
@@ -6,65 +6,100 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"strings"
 	"time"
 )
 
-// CodeStylePolicy is your strict style instructions
+// CodeStylePolicy is your strict style instructions.
+// It ends every prompt built here, so providers send prompts as they are.
 const CodeStylePolicy = `STRICT OUTPUT REQUIREMENTS:
 
-- Output exactly 3 lines.
+- Respond with a JSON array of exactly 3 objects and nothing else.
+- Each object has exactly two string fields: "name" and "reason".
+- Do NOT wrap the JSON in code fences.
 - Do NOT include any introductory sentence.
-- Do NOT explain your reasoning.
+- Do NOT explain your reasoning outside the JSON.
 - Do NOT restate the task.
-- Each line must follow this exact format:
 - Variable names must be concise and idiomatic.
 - Prefer conventional short identifiers (n, i, j, a, b, err, ctx, req, resp, fib).
 - Do NOT use verbose tutorial-style names.
 - If a shorter conventional identifier exists, use it.
 - Avoid multi-word identifiers unless absolutely necessary.
 - Names should typically be 1-2 words max.
-- The justification must be under 5 words.
+- The reason must be under 5 words.
 - No extra commentary.
-- No blank lines.
 
-<name> - <very short justification (max 5 words)>
+[{"name": "<name>", "reason": "<very short justification (max 5 words)>"}, ...]
 `
 
-// CallLLM dispatches to the appropriate LLM provider and retries until
-// exactly 3 output lines are returned. provider is "ollama" or "claude".
-func CallLLM(taskPrompt, provider string) ([]string, error) {
+// GroupStylePolicy replaces CodeStylePolicy when a whole const group is
+// renamed at once.
+const GroupStylePolicy = `STRICT OUTPUT REQUIREMENTS:
+
+- Respond with a JSON array of exactly 3 objects and nothing else.
+- Each object is one complete, coherent naming scheme for the whole group.
+- Each object has a "names" array and a "reason" string.
+- "names" holds one {"old": "<current name>", "new": "<new name>"} object for EVERY name listed under "Names to map".
+- A name may keep its current value if it is already right.
+- All members must follow the same pattern (shared prefix or suffix, same casing).
+- Keep each name's exported/unexported status.
+- Do NOT wrap the JSON in code fences.
+- Do NOT include any introductory sentence or commentary.
+- The reason must be under 8 words.
+
+[{"names": [{"old": "<name>", "new": "<name>"}, ...], "reason": "<very short justification>"}, ...]
+`
+
+func init() {
+	RegisterProvider("claude", func() (Provider, error) { return claudeCLI{}, nil })
+	RegisterProvider("ollama-cli", func() (Provider, error) { return ollamaCLI{model: "llama3:8b"}, nil })
+}
+
+// CallLLM sends taskPrompt to provider and parses the suggestions from its
+// reply, retrying when the output cannot be parsed. Providers that implement
+// JSONProvider are asked for schema-constrained output.
+func CallLLM(taskPrompt string, provider Provider) ([]Suggestion, error) {
+	return callLLM(taskPrompt, provider, suggestionSchema, parseSuggestions)
+}
+
+// callLLM is CallLLM with the output contract spelled out: the schema
+// handed to JSONProviders and the parser applied to the reply.
+func callLLM(taskPrompt string, provider Provider, schema map[string]any, parse func(string) ([]Suggestion, error)) ([]Suggestion, error) {
 	const maxRetries = 3
 
+	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		var lines []string
+		var raw string
 		var err error
-
-		switch provider {
-		case "claude":
-			lines, err = runClaude(taskPrompt)
-		default:
-			lines, err = runOllama(taskPrompt)
+		if jp, ok := provider.(JSONProvider); ok {
+			raw, err = jp.GenerateJSON(taskPrompt, schema)
+		} else {
+			raw, err = provider.Generate(taskPrompt)
 		}
 		if err != nil {
 			return nil, err
 		}
 
-		if len(lines) == 3 {
-			return lines, nil
+		suggestions, err := parse(raw)
+		if err == nil {
+			return suggestions, nil
 		}
+		lastErr = err
 
-		fmt.Fprintf(os.Stderr, "[llm] retry %d: got %d lines, expecting 3\n", attempt, len(lines))
+		fmt.Fprintf(os.Stderr, "[llm] retry %d: no suggestions in model output\n", attempt)
 		time.Sleep(500 * time.Millisecond)
 	}
 
-	return nil, fmt.Errorf("failed to get exactly 3 lines after %d attempts", maxRetries)
+	return nil, lastErr
 }
 
-// runClaude shells out to the `claude` CLI (Claude Code) using the OAuth
+// claudeCLI shells out to the `claude` CLI (Claude Code) using the OAuth
 // session already established by the user — no API key required.
-func runClaude(taskPrompt string) ([]string, error) {
-	cmd := exec.Command("claude", "-p", CodeStylePolicy+"\n\n"+taskPrompt)
+type claudeCLI struct{}
+
+func (claudeCLI) Name() string { return "claude" }
+
+func (claudeCLI) Generate(taskPrompt string) (string, error) {
+	cmd := exec.Command("claude", "-p", taskPrompt)
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
@@ -73,19 +108,25 @@ func runClaude(taskPrompt string) ([]string, error) {
 
 	if err := cmd.Run(); err != nil {
 		log.Printf("[llm] claude error: %s", stderr.String())
-		return nil, err
+		return "", err
 	}
 
-	return parseRenameOutput(stdout.String()), nil
+	return stdout.String(), nil
 }
 
-// runOllama executes the CLI and parses stdout
-func runOllama(prompt string) ([]string, error) {
+// ollamaCLI executes `ollama run` and returns its stdout
+type ollamaCLI struct {
+	model string
+}
+
+func (ollamaCLI) Name() string { return "ollama-cli" }
+
+func (o ollamaCLI) Generate(prompt string) (string, error) {
 	cmd := exec.Command(
 		"ollama",
 		"run",
-		"llama3:8b",
-		CodeStylePolicy+"\n\n"+prompt,
+		o.model,
+		prompt,
 	)
 
 	var stdout bytes.Buffer
@@ -96,23 +137,8 @@ func runOllama(prompt string) ([]string, error) {
 	err := cmd.Run()
 	if err != nil {
 		log.Printf("[llm] Ollama error: %s", stderr.String())
-		return nil, err
+		return "", err
 	}
 
-	raw := stdout.String()
-
-	return parseRenameOutput(raw), nil
-}
-
-// parseRenameOutput trims empty lines and returns lines
-func parseRenameOutput(raw string) []string {
-	lines := strings.Split(raw, "\n")
-	var out []string
-	for _, l := range lines {
-		l = strings.TrimSpace(l)
-		if l != "" {
-			out = append(out, l)
-		}
-	}
-	return out
+	return stdout.String(), nil
 }
@@ -0,0 +1,324 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Source is a parsed and type-checked view of the package containing the
+// file being renamed in.
+type Source struct {
+	Fset  *token.FileSet
+	File  *ast.File   // the file the selector refers to
+	Files []*ast.File // every loaded file of the package, File included
+	Pkg   *types.Package
+	Info  *types.Info // shared by every package Load type-checked
+
+	// Others holds the files of the other packages of the enclosing module
+	// when loaded with LoadOptions.Module.
+	Others []*ast.File
+	module bool // loaded with LoadOptions.Module
+
+	// TypeErrors holds errors reported by the type checker. They are not
+	// fatal: context is still built from whatever could be checked.
+	TypeErrors []error
+}
+
+// LoadOptions controls how much code Load reads around the target file.
+type LoadOptions struct {
+	// Module additionally loads every package of the module enclosing the
+	// file (found via go.mod), so references from other packages are
+	// collected too.
+	Module bool
+
+	// Tests adds the _test.go files of the target package, so renames
+	// reach into its tests. External test packages (package x_test) are
+	// not loaded.
+	Tests bool
+}
+
+// Load parses filename together with the other non-test files of its
+// package in the same directory (and its test files, with
+// LoadOptions.Tests) and type-checks them. Packages of the
+// enclosing module are type-checked from their source on disk; all other
+// imports from GOROOT and the module cache, so no network access or
+// compiled export data is needed.
+func Load(filename string, opts LoadOptions) (*Source, error) {
+	filename = filepath.Clean(filename)
+	dir := filepath.Dir(filename)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings, err := parseDir(fset, dir, file.Name.Name, filename, opts.Tests)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &Source{
+		Fset:  fset,
+		File:  file,
+		Files: append([]*ast.File{file}, siblings...),
+		Info: &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Implicits:  map[ast.Node]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+			Scopes:     map[ast.Node]*types.Scope{},
+		},
+		module: opts.Module,
+	}
+
+	ld := newLoader(fset, src.Info, &src.TypeErrors)
+	if root, modPath, err := findModule(absDir); err == nil {
+		ld.root, ld.modPath = root, modPath
+	}
+
+	path := ld.importPath(absDir, file.Name.Name)
+	src.Pkg = ld.check(path, src.Files)
+
+	if opts.Module && ld.root != "" {
+		dirs, err := ld.packageDirs()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirs {
+			if d == absDir {
+				continue
+			}
+			// Import errors surface as type errors in the importing
+			// package; a package nobody can import is still worth
+			// checking for its own references.
+			if _, err := ld.load(ld.importPath(d, ""), d); err != nil {
+				src.TypeErrors = append(src.TypeErrors, err)
+			}
+		}
+		src.Others = ld.filesExcept(path)
+	}
+
+	return src, nil
+}
+
+// AllFiles returns the files of the target package followed by those of
+// any other loaded packages.
+func (s *Source) AllFiles() []*ast.File {
+	return append(slices.Clip(s.Files), s.Others...)
+}
+
+// qualifier prints package-local names unqualified.
+func (s *Source) qualifier() types.Qualifier {
+	return types.RelativeTo(s.Pkg)
+}
+
+// typeString formats t relative to the loaded package.
+func (s *Source) typeString(t types.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	if b, ok := t.(*types.Basic); ok && b.Kind() == types.Invalid {
+		return "unknown"
+	}
+	return types.TypeString(t, s.qualifier())
+}
+
+// methodSet lists the methods callable on a value of type t, including
+// pointer-receiver methods when t is an addressable named type.
+func (s *Source) methodSet(t types.Type) []string {
+	if t == nil {
+		return nil
+	}
+	if _, isPtr := t.(*types.Pointer); !isPtr && !types.IsInterface(t) {
+		if _, named := t.(*types.Named); named {
+			t = types.NewPointer(t)
+		}
+	}
+	var methods []string
+	mset := types.NewMethodSet(t)
+	for i := 0; i < mset.Len(); i++ {
+		fn := mset.At(i).Obj()
+		sig := types.TypeString(fn.Type(), s.qualifier())
+		methods = append(methods, fn.Name()+strings.TrimPrefix(sig, "func"))
+	}
+	return methods
+}
+
+// calleeString describes the function called by call, e.g.
+// "func fmt.Println(a ...any) (n int, err error)" or
+// "func make([]int, int) []int" for builtins. Conversions and calls through
+// function values yield "".
+func (s *Source) calleeString(call *ast.CallExpr) string {
+	fun := ast.Unparen(call.Fun)
+	if tv, ok := s.Info.Types[fun]; ok && tv.IsType() {
+		return ""
+	}
+
+	var id *ast.Ident
+	switch f := unindex(fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return ""
+	}
+
+	switch obj := s.Info.Uses[id].(type) {
+	case *types.Func:
+		return types.ObjectString(obj, s.qualifier())
+	case *types.Builtin:
+		sig := s.typeString(s.Info.TypeOf(fun))
+		return "func " + obj.Name() + strings.TrimPrefix(sig, "func")
+	}
+	return ""
+}
+
+// unindex strips explicit instantiation from a generic function expression.
+func unindex(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return e.X
+	case *ast.IndexListExpr:
+		return e.X
+	}
+	return expr
+}
+
+// fileOf returns the loaded file containing pos, or nil.
+func (s *Source) fileOf(pos token.Pos) *ast.File {
+	for _, f := range s.AllFiles() {
+		if f.FileStart <= pos && pos <= f.FileEnd {
+			return f
+		}
+	}
+	return nil
+}
+
+// pathTo returns the nodes of the loaded file enclosing pos, innermost
+// first and ending with the *ast.File.
+func (s *Source) pathTo(pos token.Pos) []ast.Node {
+	file := s.fileOf(pos)
+	if file == nil {
+		return nil
+	}
+	var path []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil || pos < n.Pos() || n.End() <= pos {
+			return false
+		}
+		// the type of a method starts at the func keyword, before the
+		// receiver it does not contain
+		if ft, ok := n.(*ast.FuncType); ok && ft.Params != nil && pos < ft.Params.Pos() && (ft.TypeParams == nil || pos < ft.TypeParams.Pos()) {
+			return false
+		}
+		path = append(path, n)
+		return true
+	})
+	slices.Reverse(path)
+	return path
+}
+
+// enclosingFunc returns the innermost function declaration on path.
+func enclosingFunc(path []ast.Node) *ast.FuncDecl {
+	for _, n := range path {
+		if fn, ok := n.(*ast.FuncDecl); ok {
+			return fn
+		}
+	}
+	return nil
+}
+
+// objectOf is Info.ObjectOf, except that the symbol of a type switch
+// ("v" in "switch v := x.(type)"), which declares a separate variable in
+// every clause, resolves to the variable of the first clause.
+func (s *Source) objectOf(id *ast.Ident) types.Object {
+	if obj := s.Info.ObjectOf(id); obj != nil {
+		return obj
+	}
+	path := s.pathTo(id.Pos())
+	if len(path) > 2 {
+		if ts, ok := path[2].(*ast.TypeSwitchStmt); ok && ts.Assign == path[1] {
+			for _, clause := range ts.Body.List {
+				if obj := s.Info.Implicits[clause]; obj != nil {
+					return obj
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// refs returns every identifier in files that defines or uses obj, in
+// source order.
+func (s *Source) refs(obj types.Object, files ...*ast.File) []*ast.Ident {
+	var ids []*ast.Ident
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && s.Info.ObjectOf(id) == obj {
+				ids = append(ids, id)
+			}
+			return true
+		})
+	}
+	return ids
+}
+
+// position formats pos as "filepath:line:col", 1-based.
+func (s *Source) position(pos token.Pos) string {
+	p := s.Fset.Position(pos)
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// typeSpecOf returns the declaration of a package-level or local type.
+func (s *Source) typeSpecOf(obj *types.TypeName) *ast.TypeSpec {
+	for _, n := range s.pathTo(obj.Pos()) {
+		if ts, ok := n.(*ast.TypeSpec); ok && ts.Name.Pos() == obj.Pos() {
+			return ts
+		}
+	}
+	return nil
+}
+
+// callers describes every call of fn in the loaded files as
+// "caller: call-expression (file:line:col)".
+func (s *Source) callers(fn types.Object) []string {
+	var out []string
+	for _, f := range s.AllFiles() {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var id *ast.Ident
+			switch fun := unindex(ast.Unparen(call.Fun)).(type) {
+			case *ast.Ident:
+				id = fun
+			case *ast.SelectorExpr:
+				id = fun.Sel
+			}
+			if id == nil || s.Info.Uses[id] != fn {
+				return true
+			}
+			caller := "package scope"
+			if decl := enclosingFunc(s.pathTo(call.Pos())); decl != nil {
+				caller = decl.Name.Name
+			}
+			out = append(out, fmt.Sprintf("%s: %s (%s)", caller, types.ExprString(call), s.position(call.Pos())))
+			return true
+		})
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package rename
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// WorkspaceEdit and the types below mirror the Language Server Protocol
+// structures of the same name, so editors can apply a suggestion through
+// workspace/applyEdit without reimplementing rename logic.
+type WorkspaceEdit struct {
+	DocumentChanges []TextDocumentEdit `json:"documentChanges"`
+}
+
+type TextDocumentEdit struct {
+	TextDocument OptionalVersionedTextDocumentIdentifier `json:"textDocument"`
+	Edits        []TextEdit                              `json:"edits"`
+}
+
+// OptionalVersionedTextDocumentIdentifier leaves Version nil (null): the
+// edits are computed from the files on disk, not from an editor buffer.
+type OptionalVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Position is zero-based, with Character counted in UTF-16 code units as
+// LSP requires by default.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// NewWorkspaceEdit converts edits to a WorkspaceEdit holding one
+// TextDocumentEdit per file, in the order the files first appear in edits.
+// Files are read to compute UTF-16 positions, and must still contain the
+// text the edits replace.
+func NewWorkspaceEdit(edits []Edit) (*WorkspaceEdit, error) {
+	files, byFile := groupByFile(edits)
+
+	we := &WorkspaceEdit{DocumentChanges: []TextDocumentEdit{}}
+	for _, name := range files {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		fileEdits := byFile[name]
+		if _, err := applyToContent(content, fileEdits); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		uri, err := fileURI(name)
+		if err != nil {
+			return nil, err
+		}
+
+		doc := TextDocumentEdit{TextDocument: OptionalVersionedTextDocumentIdentifier{URI: uri}}
+		fileEdits = slices.SortedFunc(slices.Values(fileEdits), func(a, b Edit) int { return cmp.Compare(a.Offset, b.Offset) })
+		for _, e := range fileEdits {
+			doc.Edits = append(doc.Edits, TextEdit{
+				Range: Range{
+					Start: utf16Position(content, e.Offset),
+					End:   utf16Position(content, e.Offset+len(e.OldText)),
+				},
+				NewText: e.NewText,
+			})
+		}
+		we.DocumentChanges = append(we.DocumentChanges, doc)
+	}
+	return we, nil
+}
+
+// fileURI returns the file:// URI of the file name.
+func fileURI(name string) (string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String(), nil
+}
+
+// utf16Position converts a byte offset of content to an LSP position.
+func utf16Position(content []byte, offset int) Position {
+	before := content[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+
+	character := 0
+	for rest := before[lineStart:]; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		if n := utf16.RuneLen(r); n > 0 {
+			character += n
+		} else {
+			character++
+		}
+		rest = rest[size:]
+	}
+	return Position{Line: bytes.Count(before, []byte("\n")), Character: character}
+}
@@ -0,0 +1,46 @@
+package rename
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewWorkspaceEdit(t *testing.T) {
+	content := "package p\n\nvar s = \"héllo😀\" + s2\n\nvar s2 = \"!\"\n"
+	path := filepath.Join(t.TempDir(), "a.go")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first := strings.Index(content, "s2")
+	second := strings.LastIndex(content, "s2")
+	edits := []Edit{
+		{Filename: path, Offset: second, OldText: "s2", NewText: "suffix"},
+		{Filename: path, Offset: first, OldText: "s2", NewText: "suffix"},
+	}
+
+	we, err := NewWorkspaceEdit(edits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(we)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "var s = \"héllo😀\" + " is 20 UTF-16 code units: é is one, 😀 two
+	uri := "file://" + filepath.ToSlash(path)
+	want := `{"documentChanges":[{"textDocument":{"uri":"` + uri + `","version":null},"edits":[` +
+		`{"range":{"start":{"line":2,"character":20},"end":{"line":2,"character":22}},"newText":"suffix"},` +
+		`{"range":{"start":{"line":4,"character":4},"end":{"line":4,"character":6}},"newText":"suffix"}]}]}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	stale := []Edit{{Filename: path, Offset: 0, OldText: "s2", NewText: "x"}}
+	if _, err := NewWorkspaceEdit(stale); err == nil {
+		t.Error("stale edit accepted")
+	}
+}
@@ -0,0 +1,231 @@
+package rename
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// loader type-checks packages of one module from source, sharing a single
+// types.Info so objects compare equal across packages. It doubles as the
+// types.Importer for the packages it checks.
+type loader struct {
+	fset     *token.FileSet
+	info     *types.Info
+	errs     *[]error
+	fallback types.Importer // GOROOT and module cache
+
+	root    string // module root directory, "" outside a module
+	modPath string // module path from go.mod
+
+	pkgs    map[string]*types.Package
+	files   map[string][]*ast.File
+	loading map[string]bool
+}
+
+func newLoader(fset *token.FileSet, info *types.Info, errs *[]error) *loader {
+	return &loader{
+		fset:     fset,
+		info:     info,
+		errs:     errs,
+		fallback: importer.ForCompiler(fset, "source", nil),
+		pkgs:     map[string]*types.Package{},
+		files:    map[string][]*ast.File{},
+		loading:  map[string]bool{},
+	}
+}
+
+// Import implements types.Importer. Paths inside the module are checked
+// from the source on disk; everything else goes to the fallback importer.
+func (l *loader) Import(p string) (*types.Package, error) {
+	if l.root == "" || (p != l.modPath && !strings.HasPrefix(p, l.modPath+"/")) {
+		return l.fallback.Import(p)
+	}
+	dir := filepath.Join(l.root, filepath.FromSlash(strings.TrimPrefix(strings.TrimPrefix(p, l.modPath), "/")))
+	return l.load(p, dir)
+}
+
+// load parses and type-checks the package in dir once, under import path p.
+func (l *loader) load(p, dir string) (*types.Package, error) {
+	if pkg, ok := l.pkgs[p]; ok {
+		return pkg, nil
+	}
+	if l.loading[p] {
+		return nil, fmt.Errorf("import cycle through %q", p)
+	}
+	files, err := parseDir(l.fset, dir, "", "", false)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files for %q in %s", p, dir)
+	}
+	return l.check(p, files), nil
+}
+
+// check type-checks files as package p and records the result.
+func (l *loader) check(p string, files []*ast.File) *types.Package {
+	l.loading[p] = true
+	defer delete(l.loading, p)
+
+	conf := types.Config{
+		Importer: l,
+		Error:    func(err error) { *l.errs = append(*l.errs, err) },
+	}
+	// With an Error handler set Check keeps going after errors, and the
+	// errors themselves are collected above.
+	pkg, _ := conf.Check(p, l.fset, files, l.info)
+	l.pkgs[p] = pkg
+	l.files[p] = files
+	return pkg
+}
+
+// importPath returns the import path of the package in dir, or fallback
+// when dir is not inside the module.
+func (l *loader) importPath(dir, fallback string) string {
+	if l.root == "" {
+		return fallback
+	}
+	rel, err := filepath.Rel(l.root, dir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fallback
+	}
+	if rel == "." {
+		return l.modPath
+	}
+	return path.Join(l.modPath, filepath.ToSlash(rel))
+}
+
+// packageDirs lists the directories of the module that contain Go files,
+// skipping testdata, vendor, hidden directories and nested modules.
+func (l *loader) packageDirs() ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(l.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if p != l.root {
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		if hasGoFiles(p) {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// filesExcept returns the files of every loaded package other than skip,
+// ordered by import path.
+func (l *loader) filesExcept(skip string) []*ast.File {
+	var paths []string
+	for p := range l.files {
+		if p != skip {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	var files []*ast.File
+	for _, p := range paths {
+		files = append(files, l.files[p]...)
+	}
+	return files
+}
+
+// findModule walks up from dir to the nearest go.mod and returns the module
+// root and path.
+func findModule(dir string) (root, modPath string, err error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		f, err := os.Open(filepath.Join(d, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				line := strings.TrimSpace(sc.Text())
+				if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+					mod := strings.TrimSpace(rest)
+					if unq, err := strconv.Unquote(mod); err == nil {
+						mod = unq
+					}
+					return d, mod, nil
+				}
+			}
+			return "", "", fmt.Errorf("%s: no module directive", filepath.Join(d, "go.mod"))
+		}
+		if filepath.Dir(d) == d {
+			return "", "", errors.New("go.mod not found")
+		}
+	}
+}
+
+// parseDir parses the Go files in dir that match the default build
+// context, test files only if tests is set. When pkgName is set only files
+// of that package are kept, otherwise the package of the first file wins.
+// skip names a file to leave out.
+func parseDir(fset *token.FileSet, dir, pkgName, skip string, tests bool) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || (!tests && strings.HasSuffix(name, "_test.go")) {
+			continue
+		}
+		p := filepath.Join(dir, name)
+		if p == skip {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("parsing package file: %w", err)
+		}
+		if pkgName == "" {
+			pkgName = f.Name.Name
+		}
+		if f.Name.Name == pkgName {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") && !strings.HasSuffix(e.Name(), "_test.go") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeModule lays out files (relative path -> content) under a temp dir.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+var shopModule = map[string]string{
+	"go.mod": "module example.com/shop\n\ngo 1.22\n",
+	"order/order.go": `package order
+
+type Order struct {
+	Amt float64
+}
+`,
+	"order/discount.go": `package order
+
+func Discount(o *Order, pct float64) float64 {
+	return o.Amt * pct / 100
+}
+`,
+	"billing/billing.go": `package billing
+
+import "example.com/shop/order"
+
+func Charge(o *order.Order) float64 {
+	return o.Amt - order.Discount(o, 10)
+}
+`,
+	"cmd/shop/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/shop/billing"
+	"example.com/shop/order"
+)
+
+func main() {
+	fmt.Println(billing.Charge(&order.Order{Amt: 3}))
+}
+`,
+}
+
+func TestLoadPackageAndModule(t *testing.T) {
+	root := writeModule(t, shopModule)
+	file := filepath.Join(root, "order", "order.go")
+
+	usageFiles := func(opts LoadOptions) []string {
+		t.Helper()
+		src, err := Load(file, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(src.TypeErrors) > 0 {
+			t.Fatalf("type errors: %v", src.TypeErrors)
+		}
+		ctx, err := BuildFieldContext(src, identAt(t, src, "Amt", 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var files []string
+		for _, u := range ctx.Usages {
+			rel, _ := filepath.Rel(root, u[:strings.Index(u, ".go:")+3])
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return files
+	}
+
+	pkgOnly := usageFiles(LoadOptions{})
+	if want := []string{"order/order.go", "order/discount.go"}; !reflect.DeepEqual(pkgOnly, want) {
+		t.Errorf("package usages = %v, want %v", pkgOnly, want)
+	}
+
+	module := usageFiles(LoadOptions{Module: true})
+	want := []string{"order/order.go", "order/discount.go", "billing/billing.go", "cmd/shop/main.go"}
+	if !reflect.DeepEqual(module, want) {
+		t.Errorf("module usages = %v, want %v", module, want)
+	}
+}
+
+func TestBuildVarContextCallers(t *testing.T) {
+	root := writeModule(t, shopModule)
+	src, err := Load(filepath.Join(root, "order", "discount.go"), LoadOptions{Module: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := BuildVarContext(src, identAt(t, src, "pct", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ctx.Callers) != 1 || !strings.HasPrefix(ctx.Callers[0], "Charge: order.Discount(o, 10) (") {
+		t.Errorf("Callers = %q", ctx.Callers)
+	}
+}
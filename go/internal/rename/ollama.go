@@ -0,0 +1,118 @@
+package rename
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func init() {
+	RegisterProvider("ollama", func() (Provider, error) { return NewOllamaProvider() })
+}
+
+// OllamaProvider talks to an Ollama server through its REST API (/api/chat).
+type OllamaProvider struct {
+	Host      string         // base URL, e.g. http://127.0.0.1:11434
+	Model     string         // model tag, e.g. llama3:8b
+	Options   map[string]any // model options such as temperature or seed
+	KeepAlive string         // how long the model stays loaded, e.g. "5m"
+	Client    *http.Client
+}
+
+// NewOllamaProvider builds an OllamaProvider from the environment:
+//
+//	OLLAMA_HOST                 server address (default 127.0.0.1:11434)
+//	AI_RENAME_OLLAMA_MODEL      model tag (default llama3:8b)
+//	AI_RENAME_OLLAMA_OPTIONS    JSON object of model options
+//	AI_RENAME_OLLAMA_KEEP_ALIVE keep-alive duration passed to the server
+func NewOllamaProvider() (*OllamaProvider, error) {
+	p := &OllamaProvider{
+		Host:      normalizeBaseURL(envOr("OLLAMA_HOST", "127.0.0.1:11434")),
+		Model:     envOr("AI_RENAME_OLLAMA_MODEL", "llama3:8b"),
+		KeepAlive: os.Getenv("AI_RENAME_OLLAMA_KEEP_ALIVE"),
+	}
+	if raw := os.Getenv("AI_RENAME_OLLAMA_OPTIONS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &p.Options); err != nil {
+			return nil, fmt.Errorf("AI_RENAME_OLLAMA_OPTIONS: %w", err)
+		}
+	}
+	return p, nil
+}
+
+func (p *OllamaProvider) Name() string { return "ollama" }
+
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []ollamaMessage `json:"messages"`
+	Stream    bool            `json:"stream"`
+	Format    any             `json:"format,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+	KeepAlive string          `json:"keep_alive,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Error   string        `json:"error"`
+}
+
+// Generate sends prompt as the only message, with no system message, and
+// returns the assistant reply.
+func (p *OllamaProvider) Generate(prompt string) (string, error) {
+	req := ollamaChatRequest{
+		Model: p.Model,
+		Messages: []ollamaMessage{
+			{Role: "user", Content: prompt},
+		},
+		Options:   p.Options,
+		KeepAlive: p.KeepAlive,
+	}
+	return p.chat(req)
+}
+
+// GenerateJSON is like Generate but passes schema as the response format so
+// the server constrains the reply to matching JSON.
+func (p *OllamaProvider) GenerateJSON(prompt string, schema map[string]any) (string, error) {
+	req := ollamaChatRequest{
+		Model: p.Model,
+		Messages: []ollamaMessage{
+			{Role: "user", Content: prompt},
+		},
+		Format:    schema,
+		Options:   p.Options,
+		KeepAlive: p.KeepAlive,
+	}
+	return p.chat(req)
+}
+
+func (p *OllamaProvider) chat(req ollamaChatRequest) (string, error) {
+	reply, err := postJSON(p.Client, p.Host+"/api/chat", nil, req)
+	if err != nil {
+		return "", fmt.Errorf("ollama: %w", err)
+	}
+
+	var resp ollamaChatResponse
+	decodeErr := json.Unmarshal(reply.Body, &resp)
+	if reply.Status != http.StatusOK {
+		return "", statusError("ollama", reply.Status, resp.Error, reply.Body)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("ollama: decoding response: %w", decodeErr)
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("ollama: %s", resp.Error)
+	}
+	return resp.Message.Content, nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,127 @@
+package rename
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeOllama starts a stand-in for the Ollama REST API. handle receives the
+// decoded chat request and returns the status code and JSON body to send.
+func fakeOllama(t *testing.T, handle func(req ollamaChatRequest) (int, any)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		status, body := handle(req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOllamaProviderGenerate(t *testing.T) {
+	var got ollamaChatRequest
+	srv := fakeOllama(t, func(req ollamaChatRequest) (int, any) {
+		got = req
+		return http.StatusOK, map[string]any{
+			"model":   req.Model,
+			"message": map[string]string{"role": "assistant", "content": "n - count\ni - index\nfib - series"},
+			"done":    true,
+		}
+	})
+
+	p := &OllamaProvider{
+		Host:      srv.URL,
+		Model:     "qwen2.5-coder:7b",
+		Options:   map[string]any{"temperature": 0.1, "seed": 7},
+		KeepAlive: "10m",
+	}
+	out, err := p.Generate("rename num")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "n - count\ni - index\nfib - series" {
+		t.Errorf("Generate() = %q", out)
+	}
+
+	if got.Model != "qwen2.5-coder:7b" || got.Stream || got.KeepAlive != "10m" {
+		t.Errorf("request = %+v", got)
+	}
+	if got.Options["temperature"] != 0.1 || got.Options["seed"] != float64(7) {
+		t.Errorf("options = %v", got.Options)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content != "rename num" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+}
+
+func TestOllamaProviderError(t *testing.T) {
+	srv := fakeOllama(t, func(req ollamaChatRequest) (int, any) {
+		return http.StatusNotFound, map[string]string{"error": `model "nope" not found, try pulling it first`}
+	})
+
+	p := &OllamaProvider{Host: srv.URL, Model: "nope"}
+	_, err := p.Generate("rename num")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "HTTP 404") || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestNewOllamaProviderFromEnv(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "ollama.internal:11434/")
+	t.Setenv("AI_RENAME_OLLAMA_MODEL", "llama3.1:70b")
+	t.Setenv("AI_RENAME_OLLAMA_OPTIONS", `{"temperature":0}`)
+	t.Setenv("AI_RENAME_OLLAMA_KEEP_ALIVE", "1h")
+
+	p, err := NewOllamaProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Host != "http://ollama.internal:11434" || p.Model != "llama3.1:70b" || p.KeepAlive != "1h" {
+		t.Errorf("provider = %+v", p)
+	}
+	if v, ok := p.Options["temperature"]; !ok || v != float64(0) {
+		t.Errorf("options = %v", p.Options)
+	}
+
+	t.Setenv("AI_RENAME_OLLAMA_OPTIONS", `{not json`)
+	if _, err := NewOllamaProvider(); err == nil {
+		t.Error("expected error for malformed options")
+	}
+}
+
+func TestOllamaProviderGenerateJSON(t *testing.T) {
+	srv := fakeOllama(t, func(req ollamaChatRequest) (int, any) {
+		if format, ok := req.Format.(map[string]any); !ok || format["type"] != "object" {
+			t.Errorf("format = %v, want schema object", req.Format)
+		}
+		return http.StatusOK, map[string]any{
+			"message": map[string]string{"role": "assistant", "content": `{"suggestions":[{"name":"fib","reason":"series"}]}`},
+			"done":    true,
+		}
+	})
+
+	p := &OllamaProvider{Host: srv.URL, Model: "llama3:8b"}
+	suggestions, err := CallLLM("rename num", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Name != "fib" || suggestions[0].Reason != "series" {
+		t.Errorf("suggestions = %+v", suggestions)
+	}
+}
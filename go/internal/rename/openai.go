@@ -0,0 +1,119 @@
+package rename
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func init() {
+	RegisterProvider("openai-compat", func() (Provider, error) { return NewOpenAICompatProvider() })
+}
+
+// OpenAICompatProvider talks to any server implementing the OpenAI
+// /v1/chat/completions protocol, such as vLLM, llama.cpp server or LM Studio.
+type OpenAICompatProvider struct {
+	BaseURL string // including the version prefix, e.g. http://127.0.0.1:8000/v1
+	Model   string // may be empty for servers that host a single model
+	APIKey  string // sent as a bearer token when set
+	Client  *http.Client
+}
+
+// NewOpenAICompatProvider builds an OpenAICompatProvider from the environment:
+//
+//	AI_RENAME_OPENAI_BASE_URL  API base URL (default http://127.0.0.1:8000/v1)
+//	AI_RENAME_OPENAI_MODEL     model name
+//	AI_RENAME_OPENAI_API_KEY   bearer token (falls back to OPENAI_API_KEY)
+func NewOpenAICompatProvider() (*OpenAICompatProvider, error) {
+	return &OpenAICompatProvider{
+		BaseURL: normalizeBaseURL(envOr("AI_RENAME_OPENAI_BASE_URL", "http://127.0.0.1:8000/v1")),
+		Model:   os.Getenv("AI_RENAME_OPENAI_MODEL"),
+		APIKey:  envOr("AI_RENAME_OPENAI_API_KEY", os.Getenv("OPENAI_API_KEY")),
+	}, nil
+}
+
+func (p *OpenAICompatProvider) Name() string { return "openai-compat" }
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatRequest struct {
+	Model          string          `json:"model,omitempty"`
+	Messages       []openAIMessage `json:"messages"`
+	ResponseFormat map[string]any  `json:"response_format,omitempty"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	// Message is where vLLM puts error text instead of error.message.
+	Message string `json:"message"`
+}
+
+// Generate sends prompt alone as the user message and returns the first
+// choice.
+func (p *OpenAICompatProvider) Generate(prompt string) (string, error) {
+	req := openAIChatRequest{
+		Model: p.Model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+	}
+	return p.send(req)
+}
+
+// GenerateJSON is like Generate but requests a json_schema response format,
+// which vLLM, llama.cpp server and LM Studio enforce with guided decoding.
+func (p *OpenAICompatProvider) GenerateJSON(prompt string, schema map[string]any) (string, error) {
+	req := openAIChatRequest{
+		Model: p.Model,
+		Messages: []openAIMessage{
+			{Role: "user", Content: prompt},
+		},
+		ResponseFormat: map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   "rename_suggestions",
+				"schema": schema,
+			},
+		},
+	}
+	return p.send(req)
+}
+
+func (p *OpenAICompatProvider) send(req openAIChatRequest) (string, error) {
+	var header http.Header
+	if p.APIKey != "" {
+		header = http.Header{}
+		header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	reply, err := postJSON(p.Client, p.BaseURL+"/chat/completions", header, req)
+	if err != nil {
+		return "", fmt.Errorf("openai-compat: %w", err)
+	}
+
+	var resp openAIChatResponse
+	decodeErr := json.Unmarshal(reply.Body, &resp)
+	if reply.Status != http.StatusOK {
+		msg := resp.Message
+		if resp.Error != nil {
+			msg = resp.Error.Message
+		}
+		return "", statusError("openai-compat", reply.Status, msg, reply.Body)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("openai-compat: decoding response: %w", decodeErr)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai-compat: response has no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,92 @@
+package rename
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func fakeOpenAI(t *testing.T, handle func(r *http.Request, req openAIChatRequest) (int, any)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		status, body := handle(r, req)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenAICompatProviderGenerate(t *testing.T) {
+	srv := fakeOpenAI(t, func(r *http.Request, req openAIChatRequest) (int, any) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if req.Model != "qwen2.5-coder" || len(req.Messages) != 1 || req.Messages[0].Content != "rename amt" {
+			t.Errorf("request = %+v", req)
+		}
+		return http.StatusOK, map[string]any{
+			"choices": []map[string]any{
+				{"index": 0, "message": map[string]string{"role": "assistant", "content": "total - order sum"}},
+			},
+		}
+	})
+
+	p := &OpenAICompatProvider{BaseURL: srv.URL + "/v1", Model: "qwen2.5-coder", APIKey: "secret"}
+	out, err := p.Generate("rename amt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "total - order sum" {
+		t.Errorf("Generate() = %q", out)
+	}
+}
+
+func TestOpenAICompatProviderNoToken(t *testing.T) {
+	srv := fakeOpenAI(t, func(r *http.Request, req openAIChatRequest) (int, any) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none", got)
+		}
+		return http.StatusBadRequest, map[string]any{"object": "error", "message": "The model `x` does not exist."}
+	})
+
+	p := &OpenAICompatProvider{BaseURL: srv.URL + "/v1", Model: "x"}
+	_, err := p.Generate("rename amt")
+	if err == nil || !strings.Contains(err.Error(), "HTTP 400: The model `x` does not exist.") {
+		t.Fatalf("error = %v", err)
+	}
+}
+
+func TestOpenAICompatProviderGenerateJSON(t *testing.T) {
+	srv := fakeOpenAI(t, func(r *http.Request, req openAIChatRequest) (int, any) {
+		if req.ResponseFormat["type"] != "json_schema" {
+			t.Errorf("response_format = %v", req.ResponseFormat)
+		}
+		return http.StatusOK, map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": `{"suggestions":[{"name":"msg","reason":"summary text"}]}`}},
+			},
+		}
+	})
+
+	p := &OpenAICompatProvider{BaseURL: srv.URL + "/v1"}
+	suggestions, err := CallLLM("rename msg", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Name != "msg" || suggestions[0].Reason != "summary text" {
+		t.Errorf("suggestions = %+v", suggestions)
+	}
+}
@@ -0,0 +1,256 @@
+package rename
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// suggestionSchema is the JSON schema handed to providers that can constrain
+// their output. APIs require an object at the top level, so the array the
+// prompt asks for is wrapped in a "suggestions" field; parseSuggestions
+// accepts either shape.
+var suggestionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"suggestions": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":   map[string]any{"type": "string"},
+					"reason": map[string]any{"type": "string"},
+				},
+				"required": []string{"name", "reason"},
+			},
+		},
+	},
+	"required": []string{"suggestions"},
+}
+
+// groupSchema is the JSON schema for group renames: each suggestion maps
+// every old name of the group to a new one.
+var groupSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"suggestions": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"names": map[string]any{
+						"type": "array",
+						"items": map[string]any{
+							"type": "object",
+							"properties": map[string]any{
+								"old": map[string]any{"type": "string"},
+								"new": map[string]any{"type": "string"},
+							},
+							"required": []string{"old", "new"},
+						},
+					},
+					"reason": map[string]any{"type": "string"},
+				},
+				"required": []string{"names", "reason"},
+			},
+		},
+	},
+	"required": []string{"suggestions"},
+}
+
+// ParseError reports model output that contained no usable suggestions.
+type ParseError struct {
+	Raw string // the model output exactly as received
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parsing model output: %v\nraw output:\n%s", e.Err, e.Raw)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+var (
+	codeFenceRe = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*(.*?)```")
+	listMarkRe  = regexp.MustCompile(`^\s*(?:\d+[.)]|[-*•])\s+`)
+)
+
+// parseSuggestions extracts suggestions from raw model output. It expects a
+// JSON array of {name, reason} objects (or an object wrapping one under
+// "suggestions"), tolerating code fences, surrounding prose, numbering and
+// backticks. Output in the legacy "name - reason" line format is accepted as
+// a last resort.
+func parseSuggestions(raw string) ([]Suggestion, error) {
+	return parseWith(raw, suggestionsFromJSON, true)
+}
+
+// parseGroupSuggestions extracts group suggestions, each carrying a mapping
+// from old to new names, with the same tolerance as parseSuggestions.
+func parseGroupSuggestions(raw string) ([]Suggestion, error) {
+	return parseWith(raw, groupSuggestionsFromJSON, false)
+}
+
+func parseWith(raw string, fromJSON func(any) []Suggestion, allowLines bool) ([]Suggestion, error) {
+	text := raw
+	if m := codeFenceRe.FindStringSubmatch(raw); m != nil {
+		text = m[1]
+	}
+
+	suggestions, jsonErr := decodeSuggestions(text, fromJSON)
+	if len(suggestions) == 0 && allowLines {
+		suggestions = parseSuggestionLines(text)
+	}
+	if len(suggestions) == 0 {
+		if jsonErr == nil {
+			jsonErr = errors.New("no suggestions found")
+		}
+		return nil, &ParseError{Raw: raw, Err: jsonErr}
+	}
+	return suggestions, nil
+}
+
+// decodeSuggestions tries every '[' or '{' in text as the start of a JSON
+// value and returns the suggestions from the first one that has any.
+func decodeSuggestions(text string, fromJSON func(any) []Suggestion) ([]Suggestion, error) {
+	var lastErr error
+	for i, r := range text {
+		if r != '[' && r != '{' {
+			continue
+		}
+		var v any
+		if err := json.NewDecoder(strings.NewReader(text[i:])).Decode(&v); err != nil {
+			lastErr = err
+			continue
+		}
+		if suggestions := fromJSON(v); len(suggestions) > 0 {
+			return suggestions, nil
+		}
+	}
+	return nil, lastErr
+}
+
+func suggestionsFromJSON(v any) []Suggestion {
+	switch x := v.(type) {
+	case map[string]any:
+		if inner, ok := x["suggestions"]; ok {
+			return suggestionsFromJSON(inner)
+		}
+		if s, ok := suggestionFromJSON(x); ok {
+			return []Suggestion{s}
+		}
+	case []any:
+		var out []Suggestion
+		for _, item := range x {
+			if s, ok := suggestionFromJSON(item); ok {
+				out = appendUnique(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func suggestionFromJSON(v any) (Suggestion, bool) {
+	switch x := v.(type) {
+	case string:
+		name := cleanName(x)
+		return Suggestion{Name: name}, name != ""
+	case map[string]any:
+		name, _ := x["name"].(string)
+		reason, _ := x["reason"].(string)
+		name = cleanName(name)
+		return Suggestion{Name: name, Reason: strings.TrimSpace(reason)}, name != ""
+	}
+	return Suggestion{}, false
+}
+
+// parseSuggestionLines handles "name - reason" lines, optionally numbered or
+// bulleted.
+func parseSuggestionLines(text string) []Suggestion {
+	var out []Suggestion
+	for _, line := range strings.Split(text, "\n") {
+		line = listMarkRe.ReplaceAllString(strings.TrimSpace(line), "")
+		for _, sep := range []string{" - ", " — ", ": "} {
+			if idx := strings.Index(line, sep); idx > 0 {
+				name := cleanName(line[:idx])
+				if name != "" && !strings.ContainsAny(name, " \t") {
+					out = appendUnique(out, Suggestion{Name: name, Reason: strings.TrimSpace(line[idx+len(sep):])})
+				}
+				break
+			}
+		}
+	}
+	return out
+}
+
+// cleanName strips list markers, backticks and quotes a model may wrap
+// around a name.
+func cleanName(s string) string {
+	s = listMarkRe.ReplaceAllString(strings.TrimSpace(s), "")
+	return strings.Trim(s, "`'\" \t*")
+}
+
+func appendUnique(list []Suggestion, s Suggestion) []Suggestion {
+	for _, existing := range list {
+		if existing.Name == s.Name {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+func groupSuggestionsFromJSON(v any) []Suggestion {
+	switch x := v.(type) {
+	case map[string]any:
+		if inner, ok := x["suggestions"]; ok {
+			return groupSuggestionsFromJSON(inner)
+		}
+		if s, ok := groupSuggestionFromJSON(x); ok {
+			return []Suggestion{s}
+		}
+	case []any:
+		var out []Suggestion
+		for _, item := range x {
+			if m, ok := item.(map[string]any); ok {
+				if s, ok := groupSuggestionFromJSON(m); ok {
+					out = append(out, s)
+				}
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// groupSuggestionFromJSON accepts "names" as a list of {old, new} pairs or
+// as a plain {old: new} object.
+func groupSuggestionFromJSON(m map[string]any) (Suggestion, bool) {
+	mapping := map[string]string{}
+	switch names := m["names"].(type) {
+	case []any:
+		for _, item := range names {
+			pair, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			old, _ := pair["old"].(string)
+			name, _ := pair["new"].(string)
+			if old, name = cleanName(old), cleanName(name); old != "" && name != "" {
+				mapping[old] = name
+			}
+		}
+	case map[string]any:
+		for old, v := range names {
+			if name, ok := v.(string); ok && cleanName(name) != "" {
+				mapping[cleanName(old)] = cleanName(name)
+			}
+		}
+	}
+	if len(mapping) == 0 {
+		return Suggestion{}, false
+	}
+	reason, _ := m["reason"].(string)
+	return Suggestion{Reason: strings.TrimSpace(reason), Mapping: mapping}, true
+}
@@ -0,0 +1,82 @@
+package rename
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSuggestions(t *testing.T) {
+	want := []Suggestion{
+		{Name: "fib", Reason: "fibonacci series"},
+		{Name: "seq", Reason: "number sequence"},
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		want []Suggestion
+	}{
+		{
+			name: "plain array",
+			raw:  `[{"name":"fib","reason":"fibonacci series"},{"name":"seq","reason":"number sequence"}]`,
+			want: want,
+		},
+		{
+			name: "wrapped object",
+			raw:  `{"suggestions":[{"name":"fib","reason":"fibonacci series"},{"name":"seq","reason":"number sequence"}]}`,
+			want: want,
+		},
+		{
+			name: "code fence and prose",
+			raw:  "Here are my suggestions:\n```json\n[{\"name\":\"fib\",\"reason\":\"fibonacci series\"},\n {\"name\":\"seq\",\"reason\":\"number sequence\"}]\n```\nHope this helps!",
+			want: want,
+		},
+		{
+			name: "backticks and numbering inside names",
+			raw:  `[{"name":"1. ` + "`fib`" + `","reason":"fibonacci series"},{"name":"2) seq","reason":"number sequence"}]`,
+			want: want,
+		},
+		{
+			name: "reason containing a dash",
+			raw:  `[{"name":"total","reason":"sum - after discount"}]`,
+			want: []Suggestion{{Name: "total", Reason: "sum - after discount"}},
+		},
+		{
+			name: "duplicates dropped",
+			raw:  `[{"name":"fib","reason":"fibonacci series"},{"name":"fib","reason":"again"},{"name":"seq","reason":"number sequence"}]`,
+			want: want,
+		},
+		{
+			name: "legacy numbered lines",
+			raw:  "1. `fib` - fibonacci series\n2. seq - number sequence\n",
+			want: want,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSuggestions(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSuggestions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSuggestionsError(t *testing.T) {
+	raw := "I think you should call it something nicer."
+	_, err := parseSuggestions(raw)
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *ParseError", err)
+	}
+	if perr.Raw != raw || !strings.Contains(err.Error(), raw) {
+		t.Errorf("error does not carry the raw output: %v", err)
+	}
+}
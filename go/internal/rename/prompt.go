@@ -1,19 +1,60 @@
 package rename
 
 import (
+	"fmt"
 	"strings"
 )
 
+// maxListItems caps each list in a prompt so that widely used identifiers
+// loaded with module-wide context don't blow up the prompt.
+const maxListItems = 20
+
+// writeList writes items as "- item" lines, "- none" when empty, and
+// elides everything past maxListItems.
+func writeList(b *strings.Builder, items []string) {
+	if len(items) == 0 {
+		b.WriteString("- none\n")
+	}
+	for i, item := range items {
+		if i == maxListItems {
+			fmt.Fprintf(b, "- ... and %d more\n", len(items)-maxListItems)
+			break
+		}
+		b.WriteString("- " + item + "\n")
+	}
+}
+
+// kindConventions tells the model how Go names each kind of variable.
+var kindConventions = map[string]string{
+	"receiver":            "Receivers are one or two letters abbreviating the type, never this or self.",
+	"parameter":           "Parameters appear in the function's documentation, so they may be a little more descriptive than locals.",
+	"named result":        "Named results document what is returned; prefer conventional names such as n, err and ok.",
+	"range key":           "Range keys are conventionally i, j, k for indexes, or a short name for map keys.",
+	"range value":         "Range values are usually the singular of the collection they iterate.",
+	"type-switch binding": "Type-switch bindings usually reuse the name of the switched expression or abbreviate the case type.",
+	"select-case binding": "Received values are named for what the channel carries; the second value is ok.",
+}
+
 func BuildPrompt(ctx *VarContext) string {
 	var b strings.Builder
 
 	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
-	b.WriteString("Your task is to suggest better variable names.\n\n")
+	b.WriteString("Your task is to suggest better variable names.\n")
+	if hint, ok := kindConventions[ctx.Kind]; ok {
+		b.WriteString(hint + "\n")
+	}
+	if ctx.Captured {
+		b.WriteString("The variable is captured by a closure, so its name must stay clear where it is read far from its declaration.\n")
+	}
+	b.WriteString("\n")
 
 	b.WriteString("Variable to rename:\n")
 	b.WriteString("- Name: " + ctx.VarName + "\n")
 	b.WriteString("- Scope: " + ctx.Scope + "\n")
 	b.WriteString("- Kind: " + ctx.Kind + "\n")
+	if ctx.Captured {
+		b.WriteString("- Captured by closure: yes\n")
+	}
 	b.WriteString("- Type: " + ctx.VarType + "\n\n")
 
 	b.WriteString("Context:\n-----------\n")
@@ -27,44 +68,28 @@ func BuildPrompt(ctx *VarContext) string {
 	b.WriteString("\n")
 
 	b.WriteString("Assignments:\n")
-	if len(ctx.Assignments) == 0 {
-		b.WriteString("- none\n")
-	}
-	for _, a := range ctx.Assignments {
-		b.WriteString("- " + a + "\n")
-	}
+	writeList(&b, ctx.Assignments)
 
 	b.WriteString("\nUsages:\n")
-	if len(ctx.Usages) == 0 {
-		b.WriteString("- none\n")
-	}
-	for _, u := range ctx.Usages {
-		b.WriteString("- " + u + "\n")
-	}
+	writeList(&b, ctx.Usages)
+
+	b.WriteString("\nMethod Set:\n")
+	writeList(&b, ctx.MethodSet)
+
+	b.WriteString("\nCalls Involving Variable:\n")
+	writeList(&b, ctx.Calls)
+
+	b.WriteString("\nCallers of Function:\n")
+	writeList(&b, ctx.Callers)
 
 	b.WriteString("\nRelated Identifiers:\n")
-	if len(ctx.RelatedIdentifiers) == 0 {
-		b.WriteString("- none\n")
-	}
-	for _, r := range ctx.RelatedIdentifiers {
-		b.WriteString("- " + r + "\n")
-	}
+	writeList(&b, ctx.RelatedIdentifiers)
 
 	b.WriteString("\nImports in Scope:\n")
-	if len(ctx.Imports) == 0 {
-		b.WriteString("- none\n")
-	}
-	for _, i := range ctx.Imports {
-		b.WriteString("- " + i + "\n")
-	}
+	writeList(&b, ctx.Imports)
 
 	b.WriteString("\nFile Comments:\n")
-	if len(ctx.FileComments) == 0 {
-		b.WriteString("- none\n")
-	}
-	for _, c := range ctx.FileComments {
-		b.WriteString("- " + c + "\n")
-	}
+	writeList(&b, ctx.FileComments)
 
 	b.WriteString(CodeStylePolicy)
 
@@ -76,13 +101,24 @@ type TypeContext struct {
 	TypeName    string
 	Fields      []string
 	StructDoc   string
+
+	// Interface types list their methods and the loaded types that
+	// implement them instead of fields.
+	Interface       bool
+	Methods         []string
+	Implementations []string
 }
 
 func BuildTypePrompt(ctx *TypeContext) string {
 	var b strings.Builder
 
 	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
-	b.WriteString("Your task is to suggest better struct type names.\n\n")
+	if ctx.Interface {
+		b.WriteString("Your task is to suggest better interface type names.\n")
+		b.WriteString("One-method interfaces are conventionally named after the method plus -er (Reader, Stringer).\n\n")
+	} else {
+		b.WriteString("Your task is to suggest better struct type names.\n\n")
+	}
 
 	b.WriteString("Type to rename:\n")
 	b.WriteString("- Name: " + ctx.TypeName + "\n\n")
@@ -91,15 +127,17 @@ func BuildTypePrompt(ctx *TypeContext) string {
 	b.WriteString("Package: " + ctx.PackageName + "\n\n")
 
 	if ctx.StructDoc != "" {
-		b.WriteString("Struct doc: " + ctx.StructDoc + "\n\n")
+		b.WriteString("Type doc: " + ctx.StructDoc + "\n\n")
 	}
 
-	b.WriteString("Fields:\n")
-	if len(ctx.Fields) == 0 {
-		b.WriteString("- none\n")
-	}
-	for _, f := range ctx.Fields {
-		b.WriteString("- " + f + "\n")
+	if ctx.Interface {
+		b.WriteString("Methods:\n")
+		writeList(&b, ctx.Methods)
+		b.WriteString("\nImplementations:\n")
+		writeList(&b, ctx.Implementations)
+	} else {
+		b.WriteString("Fields:\n")
+		writeList(&b, ctx.Fields)
 	}
 
 	b.WriteString(CodeStylePolicy)
@@ -126,14 +164,266 @@ func BuildFieldPrompt(ctx *FieldContext) string {
 	}
 
 	b.WriteString("Usages (file:line:col):\n")
-	if len(ctx.Usages) == 0 {
-		b.WriteString("- none\n")
+	writeList(&b, ctx.Usages)
+
+	b.WriteString(CodeStylePolicy)
+
+	return b.String()
+}
+
+func BuildFuncPrompt(ctx *FuncContext) string {
+	var b strings.Builder
+
+	kind := "function"
+	if ctx.Receiver != "" {
+		kind = "method"
+	}
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest better " + kind + " names.\n")
+	b.WriteString("Names should say what the " + kind + " does, use MixedCaps, and keep the current exported/unexported status.\n\n")
+
+	b.WriteString(strings.ToUpper(kind[:1]) + kind[1:] + " to rename:\n")
+	b.WriteString("- Name: " + ctx.FuncName + "\n")
+	if ctx.Receiver != "" {
+		b.WriteString("- Receiver: " + ctx.Receiver + "\n")
+	}
+	b.WriteString("- Signature: " + ctx.Signature + "\n")
+	if ctx.Doc != "" {
+		b.WriteString("- Doc: " + ctx.Doc + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Context:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+
+	b.WriteString(fmt.Sprintf("Body (%d top-level statements):\n", ctx.Statements))
+	b.WriteString("Calls:\n")
+	writeList(&b, ctx.Calls)
+	b.WriteString("\nReturns:\n")
+	writeList(&b, ctx.Returns)
+
+	b.WriteString("\nCall Sites:\n")
+	writeList(&b, ctx.CallSites)
+
+	if ctx.Receiver != "" {
+		b.WriteString("\nInterfaces Satisfied (renaming breaks these):\n")
+		writeList(&b, ctx.Implements)
+	}
+
+	b.WriteString(CodeStylePolicy)
+
+	return b.String()
+}
+
+func BuildInterfaceMethodPrompt(ctx *InterfaceContext) string {
+	var b strings.Builder
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest better names for a method of interface " + ctx.InterfaceName + ".\n")
+	b.WriteString("Every implementation listed below is renamed with it, so the name must fit all of them. Keep the current exported/unexported status.\n\n")
+
+	b.WriteString("Interface method to rename:\n")
+	b.WriteString("- Name: " + ctx.MethodName + "\n")
+	b.WriteString("- Signature: " + ctx.Signature + "\n")
+	if ctx.Doc != "" {
+		b.WriteString("- Doc: " + ctx.Doc + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Context:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+	if ctx.InterfaceDoc != "" {
+		b.WriteString("Interface doc: " + ctx.InterfaceDoc + "\n\n")
+	}
+
+	b.WriteString("Interface Methods:\n")
+	writeList(&b, ctx.Methods)
+
+	b.WriteString("\nImplementations:\n")
+	writeList(&b, ctx.Implementations)
+
+	b.WriteString("\nCall Sites:\n")
+	writeList(&b, ctx.CallSites)
+
+	b.WriteString(CodeStylePolicy)
+
+	return b.String()
+}
+
+func BuildTypeParamPrompt(ctx *TypeParamContext) string {
+	var b strings.Builder
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest better names for a type parameter of " + ctx.Owner + ".\n")
+	b.WriteString("Go type parameters are usually a single upper-case letter hinting at their role (T, E for elements, K and V for keys and values, S for slices). ")
+	b.WriteString("Use a short descriptive MixedCaps name (Key, Num) only when several parameters would otherwise be ambiguous.\n\n")
+
+	b.WriteString("Type parameter to rename:\n")
+	b.WriteString("- Name: " + ctx.Name + "\n")
+	b.WriteString("- Constraint: " + ctx.Constraint + "\n")
+	b.WriteString("- Declared by: " + ctx.Owner + "\n")
+	if ctx.Doc != "" {
+		b.WriteString("- Doc: " + ctx.Doc + "\n")
 	}
-	for _, u := range ctx.Usages {
-		b.WriteString("- " + u + "\n")
+	b.WriteString("\n")
+
+	b.WriteString("Context:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+
+	b.WriteString("Other Type Parameters:\n")
+	writeList(&b, ctx.Siblings)
+
+	b.WriteString("\nUsed In Signature:\n")
+	writeList(&b, ctx.Signature)
+
+	if len(ctx.Methods) > 0 {
+		b.WriteString("\nMethods:\n")
+		writeList(&b, ctx.Methods)
+	} else {
+		b.WriteString("\nUsed In Body:\n")
+		writeList(&b, ctx.Body)
+	}
+
+	b.WriteString(CodeStylePolicy)
+
+	return b.String()
+}
+
+func BuildImportPrompt(ctx *ImportContext) string {
+	var b strings.Builder
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest a better local name (alias) for an import.\n")
+	b.WriteString("Go import names are short, lower case and a single word: no underscores, no mixedCaps. ")
+	b.WriteString("Prefer the package's own name unless it collides or is ambiguous, e.g. crand and mrand for crypto/rand and math/rand.\n\n")
+
+	b.WriteString("Import to rename:\n")
+	b.WriteString("- Name: " + ctx.Name + "\n")
+	b.WriteString("- Path: " + ctx.Path + "\n")
+	b.WriteString("- Package name: " + ctx.Default + "\n")
+	if ctx.Aliased {
+		b.WriteString("- Currently aliased: yes\n")
 	}
+	b.WriteString("\n")
+
+	b.WriteString("Context:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+
+	b.WriteString("Members Used:\n")
+	writeList(&b, ctx.Members)
+
+	b.WriteString("\nOther Imports:\n")
+	writeList(&b, ctx.Imports)
+
+	b.WriteString("\nPackage-Level Names (must not collide):\n")
+	writeList(&b, ctx.Scope)
 
 	b.WriteString(CodeStylePolicy)
 
 	return b.String()
 }
+
+func BuildReceiverPrompt(ctx *ReceiverContext) string {
+	var b strings.Builder
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest a method receiver name for type " + ctx.TypeName + ".\n")
+	b.WriteString("The same name will be used by EVERY method of the type. Go receivers are short, usually a one- or two-letter abbreviation of the type, and never \"this\" or \"self\".\n\n")
+
+	b.WriteString("Receiver to rename:\n")
+	b.WriteString("- Name: " + ctx.Receiver + "\n")
+	b.WriteString("- Type: " + ctx.TypeName + "\n")
+	if ctx.TypeDoc != "" {
+		b.WriteString("- Doc: " + ctx.TypeDoc + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Context:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+
+	b.WriteString("Receiver Names In Use:\n")
+	writeList(&b, ctx.Current)
+
+	b.WriteString("\nMethods:\n")
+	writeList(&b, ctx.Methods)
+
+	b.WriteString(CodeStylePolicy)
+
+	return b.String()
+}
+
+func BuildDeclPrompt(ctx *DeclContext) string {
+	var b strings.Builder
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest better " + ctx.Scope + "-level " + ctx.Kind + " names.\n")
+	if len(ctx.Group) > 0 {
+		b.WriteString("The " + ctx.Kind + " is part of a declaration block: keep the new name consistent with its siblings (shared prefix, type name, casing).\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(strings.ToUpper(ctx.Kind[:1]) + ctx.Kind[1:] + " to rename:\n")
+	b.WriteString("- Name: " + ctx.Name + "\n")
+	b.WriteString("- Type: " + ctx.Type + "\n")
+	if ctx.Value != "" {
+		b.WriteString("- Value: " + ctx.Value + "\n")
+	}
+	if ctx.IotaPattern != "" {
+		b.WriteString("- Iota pattern: " + ctx.IotaPattern + "\n")
+	}
+	if ctx.Doc != "" {
+		b.WriteString("- Doc: " + ctx.Doc + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Context:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+
+	b.WriteString("Declaration Block:\n")
+	writeList(&b, ctx.Group)
+
+	b.WriteString("\nReferences:\n")
+	writeList(&b, ctx.References)
+
+	b.WriteString(CodeStylePolicy)
+
+	return b.String()
+}
+
+func BuildGroupPrompt(ctx *GroupContext) string {
+	var b strings.Builder
+
+	b.WriteString("You are a senior Go engineer writing production-grade code.\n\n")
+	b.WriteString("Your task is to suggest coherent new names for a whole group of related constants")
+	if ctx.TypeName != "" {
+		b.WriteString(" and their type")
+	}
+	b.WriteString(".\n\n")
+
+	if ctx.TypeName != "" {
+		b.WriteString("Type:\n")
+		b.WriteString("- Name: " + ctx.TypeName + "\n")
+		if ctx.TypeDoc != "" {
+			b.WriteString("- Doc: " + ctx.TypeDoc + "\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Constants:\n")
+	writeList(&b, ctx.Members)
+
+	b.WriteString("\nNames to map:\n")
+	for _, n := range ctx.Names {
+		b.WriteString("- " + n + "\n")
+	}
+
+	b.WriteString("\nContext:\n-----------\n")
+	b.WriteString("Package: " + ctx.PackageName + "\n\n")
+	b.WriteString("References:\n")
+	writeList(&b, ctx.References)
+
+	b.WriteString(GroupStylePolicy)
+
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package rename
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Provider is an LLM backend that turns a rename prompt into raw model output.
+type Provider interface {
+	// Name returns the name the provider is registered under.
+	Name() string
+	// Generate sends prompt to the model and returns its raw response text.
+	Generate(prompt string) (string, error)
+}
+
+// JSONProvider is implemented by providers whose backend can constrain the
+// reply to a JSON schema (JSON mode, structured outputs or tool use).
+type JSONProvider interface {
+	Provider
+	// GenerateJSON is like Generate but asks for output matching schema.
+	GenerateJSON(prompt string, schema map[string]any) (string, error)
+}
+
+// ContextProvider is implemented by providers that work from the context
+// a prompt was built from rather than from its text.
+type ContextProvider interface {
+	Provider
+	// GenerateFromContext is like Generate but also receives the context,
+	// e.g. a *VarContext or *FuncContext.
+	GenerateFromContext(prompt string, ctx any) (string, error)
+}
+
+// withContext returns provider, bound to ctx if it is a ContextProvider.
+func withContext(provider Provider, ctx any) Provider {
+	if cp, ok := provider.(ContextProvider); ok {
+		return boundProvider{cp, ctx}
+	}
+	return provider
+}
+
+// boundProvider hands the same context to every Generate call.
+type boundProvider struct {
+	ContextProvider
+	ctx any
+}
+
+func (p boundProvider) Generate(prompt string) (string, error) {
+	return p.GenerateFromContext(prompt, p.ctx)
+}
+
+// ProviderFactory constructs a ready-to-use Provider.
+type ProviderFactory func() (Provider, error)
+
+var providers = map[string]ProviderFactory{}
+
+// RegisterProvider makes a provider available under name. It is meant to be
+// called from init functions and panics if name is already taken.
+func RegisterProvider(name string, factory ProviderFactory) {
+	if _, dup := providers[name]; dup {
+		panic(fmt.Sprintf("rename: provider %q registered twice", name))
+	}
+	providers[name] = factory
+}
+
+// NewProvider constructs the provider registered under name.
+func NewProvider(name string) (Provider, error) {
+	factory, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+	return factory()
+}
+
+// ProviderNames returns the registered provider names in sorted order.
+func ProviderNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,124 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// ReceiverContext holds context for renaming the receiver of every method
+// of a type at once, so the methods keep using the same name
+type ReceiverContext struct {
+	PackageName string
+	TypeName    string
+	TypeDoc     string
+	Receiver    string   // name of the selected receiver
+	Current     []string // distinct receiver names in use, "o: 2 methods"
+	Methods     []string // "(o *OrderStruct) ApplyDiscount(pct float64) float64: 2 uses"
+
+	methods []*types.Func // methods whose receiver is named, and so renamed
+}
+
+// BuildReceiverContext builds context for the method receiver ident refers
+// to, gathering every method declared on the receiver's type.
+func BuildReceiverContext(src *Source, ident *ast.Ident) (*ReceiverContext, error) {
+	recv, ok := src.Info.ObjectOf(ident).(*types.Var)
+	if !ok || src.receiverDecl(recv) == nil {
+		return nil, fmt.Errorf("%q is not a method receiver", ident.Name)
+	}
+
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("receiver %q has no named type", ident.Name)
+	}
+	named = named.Origin()
+
+	ctx := &ReceiverContext{
+		PackageName: src.Pkg.Name(),
+		TypeName:    named.Obj().Name(),
+		Receiver:    recv.Name(),
+	}
+	if ts := src.typeSpecOf(named.Obj()); ts != nil {
+		ctx.TypeDoc = typeDoc(src.pathTo(ts.Pos()), ts)
+	}
+
+	var names []string
+	count := map[string]int{}
+	for i := 0; i < named.NumMethods(); i++ {
+		m := named.Method(i)
+		r := m.Type().(*types.Signature).Recv()
+		sig := strings.TrimPrefix(src.typeString(m.Type()), "func")
+		if r.Name() == "" || r.Name() == "_" {
+			ctx.Methods = append(ctx.Methods, fmt.Sprintf("(%s) %s%s: unnamed receiver", src.typeString(r.Type()), m.Name(), sig))
+			continue
+		}
+		uses := len(src.refs(r, src.fileOf(r.Pos()))) - 1 // minus the declaration
+		ctx.Methods = append(ctx.Methods, fmt.Sprintf("(%s %s) %s%s: %d uses", r.Name(), src.typeString(r.Type()), m.Name(), sig, uses))
+		ctx.methods = append(ctx.methods, m)
+		if count[r.Name()] == 0 {
+			names = append(names, r.Name())
+		}
+		count[r.Name()]++
+	}
+	for _, n := range names {
+		ctx.Current = append(ctx.Current, fmt.Sprintf("%s: %d methods", n, count[n]))
+	}
+
+	return ctx, nil
+}
+
+// receiverDecl returns the method declaration whose receiver is v, or nil
+// if v is not a receiver.
+func (s *Source) receiverDecl(v *types.Var) *ast.FuncDecl {
+	path := s.pathTo(v.Pos())
+	for i, n := range path {
+		if fl, ok := n.(*ast.FieldList); ok {
+			if i+1 < len(path) {
+				if decl, ok := path[i+1].(*ast.FuncDecl); ok && decl.Recv == fl {
+					return decl
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// validateReceiver reports why name cannot become the receiver of every
+// method in ctx, or nil if it can. Methods already using name are left as
+// they are, so unifying on one of the current names is allowed.
+func validateReceiver(src *Source, ctx *ReceiverContext, name string) error {
+	changed := false
+	for _, m := range ctx.methods {
+		r := m.Type().(*types.Signature).Recv()
+		if r.Name() == name {
+			continue
+		}
+		changed = true
+
+		scope := objectScope(src, r)
+		delete(scope, r.Name())
+		if err := validateName(r.Name(), name, false, scope); err != nil {
+			return fmt.Errorf("in %s: %v", m.Name(), err)
+		}
+	}
+	if !changed {
+		return fmt.Errorf("same as the current name")
+	}
+	return nil
+}
+
+// edits renames the receiver, and every use of it, in all methods of ctx.
+func (ctx *ReceiverContext) edits(src *Source, name string) []Edit {
+	var ids []*ast.Ident
+	for _, m := range ctx.methods {
+		r := m.Type().(*types.Signature).Recv()
+		ids = append(ids, src.refs(r, src.fileOf(r.Pos()))...)
+	}
+	return src.identEdits(ids, name)
+}
@@ -0,0 +1,79 @@
+package rename
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunRenamesReceiverInEveryMethod(t *testing.T) {
+	p := &stubProvider{replies: []string{
+		`[{"name":"o","reason":"already used"},{"name":"ord","reason":"order"}]`,
+	}}
+
+	result, err := Run("../../testdata/order.go", Selector{Kind: "funcvar", Func: "ApplyDiscount", Var: "o"}, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result.Debug.Prompt, "(o *OrderStruct) PrintSummary(): 3 uses") {
+		t.Errorf("prompt does not list PrintSummary:\n%s", result.Debug.Prompt)
+	}
+	if len(result.Suggestions) != 1 || result.Suggestions[0].Name != "ord" {
+		t.Fatalf("suggestions = %+v", result.Suggestions)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0].Reason != "same as the current name" {
+		t.Errorf("rejected = %+v", result.Rejected)
+	}
+
+	var lines []int
+	for _, e := range result.Suggestions[0].Edits {
+		if e.OldText != "o" || e.NewText != "ord" {
+			t.Errorf("edit = %+v", e)
+		}
+		lines = append(lines, e.Line)
+	}
+	if want := []int{14, 15, 16, 21, 22, 22, 22}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("edited lines = %v, want %v", lines, want)
+	}
+}
+
+const mixedReceiverSrc = `package p
+
+type Tree struct{ size int }
+
+func (t Tree) Len() int { return t.size }
+
+func (tr *Tree) Grow() {
+	x := 1
+	tr.size += x
+}
+
+func (Tree) Kind() string { return "tree" }
+`
+
+func TestReceiverContextMixedNames(t *testing.T) {
+	src := writeSource(t, mixedReceiverSrc)
+	ctx, err := BuildReceiverContext(src, identAt(t, src, "tr", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"t: 1 methods", "tr: 1 methods"}; !reflect.DeepEqual(ctx.Current, want) {
+		t.Errorf("Current = %v, want %v", ctx.Current, want)
+	}
+	if want := "(Tree) Kind() string: unnamed receiver"; ctx.Methods[2] != want {
+		t.Errorf("Methods[2] = %q, want %q", ctx.Methods[2], want)
+	}
+
+	if err := validateReceiver(src, ctx, "t"); err != nil {
+		t.Errorf(`unifying on "t": %v`, err)
+	}
+	if err := validateReceiver(src, ctx, "x"); err == nil || !strings.Contains(err.Error(), `in Grow: collides with variable "x"`) {
+		t.Errorf(`validateReceiver("x") = %v`, err)
+	}
+
+	edits := ctx.edits(src, "t")
+	if len(edits) != 2 || edits[0].OldText != "tr" {
+		t.Errorf("edits = %+v", edits)
+	}
+}
@@ -0,0 +1,130 @@
+package rename
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+func init() {
+	RegisterProvider("replay", func() (Provider, error) { return NewReplayProvider() })
+}
+
+// ReplayProvider answers prompts with responses recorded earlier, stored
+// one per file in Dir and keyed by a hash of the prompt, so the whole
+// pipeline can run in tests without a model or network. With Record set,
+// prompts that have no fixture yet are sent to Record and its responses
+// saved.
+type ReplayProvider struct {
+	Dir    string
+	Record Provider
+}
+
+// NewReplayProvider builds a ReplayProvider from the environment:
+//
+//	AI_RENAME_FIXTURES fixtures directory (default testdata/fixtures)
+//	AI_RENAME_RECORD   provider recording missing fixtures, e.g. ollama
+func NewReplayProvider() (*ReplayProvider, error) {
+	p := &ReplayProvider{Dir: envOr("AI_RENAME_FIXTURES", filepath.Join("testdata", "fixtures"))}
+	if name := os.Getenv("AI_RENAME_RECORD"); name != "" {
+		if name == "replay" {
+			return nil, fmt.Errorf("AI_RENAME_RECORD: cannot record from the replay provider")
+		}
+		record, err := NewProvider(name)
+		if err != nil {
+			return nil, fmt.Errorf("AI_RENAME_RECORD: %w", err)
+		}
+		p.Record = record
+	}
+	return p, nil
+}
+
+func (p *ReplayProvider) Name() string { return "replay" }
+
+// fixture is the file a response is recorded in. The prompt is kept for
+// whoever reads or reviews the fixture; the schema only feeds the key.
+type fixture struct {
+	Prompt   string         `json:"prompt"`
+	Schema   map[string]any `json:"-"`
+	Response string         `json:"response"`
+}
+
+// Generate returns the response recorded for prompt.
+func (p *ReplayProvider) Generate(prompt string) (string, error) {
+	return p.replay(fixture{Prompt: prompt})
+}
+
+// GenerateJSON returns the response recorded for prompt and schema,
+// recording through GenerateJSON when the recorder supports it.
+func (p *ReplayProvider) GenerateJSON(prompt string, schema map[string]any) (string, error) {
+	return p.replay(fixture{Prompt: prompt, Schema: schema})
+}
+
+func (p *ReplayProvider) replay(f fixture) (string, error) {
+	key, err := fixtureKey(f)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(p.Dir, key+".json")
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var recorded fixture
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		return recorded.Response, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return "", err
+	case p.Record == nil:
+		return "", fmt.Errorf("no fixture %s for this prompt; set AI_RENAME_RECORD to record it", path)
+	}
+
+	if jp, ok := p.Record.(JSONProvider); ok && f.Schema != nil {
+		f.Response, err = jp.GenerateJSON(f.Prompt, f.Schema)
+	} else {
+		f.Response, err = p.Record.Generate(f.Prompt)
+	}
+	if err != nil {
+		return "", err
+	}
+	data, err = json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(p.Dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	return f.Response, nil
+}
+
+// goPath matches the directories of a Go file path, as in
+// ../../testdata/order.go:14:6, with the base name as its submatch.
+var goPath = regexp.MustCompile(`[^\s"'(]*[/\\]([\w.-]+\.go)\b`)
+
+// fixtureKey hashes the prompt and schema of f. Prompts mention files by
+// the path they were loaded with, which depends on the working directory,
+// so only their base names are hashed.
+func fixtureKey(f fixture) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(goPath.ReplaceAllString(f.Prompt, "$1")))
+	if f.Schema != nil {
+		schema, err := json.Marshal(f.Schema) // map keys are sorted
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte{0})
+		h.Write(schema)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
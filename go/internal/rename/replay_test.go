@@ -0,0 +1,56 @@
+package rename
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReplayProviderRecords(t *testing.T) {
+	dir := t.TempDir()
+	stub := &stubProvider{replies: []string{`[{"name":"n","reason":"count"}]`}}
+
+	recorder := &ReplayProvider{Dir: dir, Record: stub}
+	if out, err := recorder.Generate("rename x"); err != nil || out != stub.replies[0] {
+		t.Fatalf("Generate() = %q, %v", out, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("fixtures = %v, %v", entries, err)
+	}
+
+	replayer := &ReplayProvider{Dir: dir}
+	if out, err := replayer.Generate("rename x"); err != nil || out != stub.replies[0] {
+		t.Errorf("replayed Generate() = %q, %v", out, err)
+	}
+	if len(stub.prompts) != 1 {
+		t.Errorf("recorder called %d times, want 1", len(stub.prompts))
+	}
+
+	_, err = replayer.Generate("rename y")
+	if err == nil || !strings.Contains(err.Error(), "AI_RENAME_RECORD") {
+		t.Errorf("missing fixture: err = %v", err)
+	}
+	// the schema is part of the key
+	if _, err := replayer.GenerateJSON("rename x", suggestionSchema); err == nil {
+		t.Error("GenerateJSON replayed a fixture recorded without a schema")
+	}
+}
+
+func TestFixtureKeyIgnoresDirectories(t *testing.T) {
+	prompt := func(path string) fixture {
+		return fixture{Prompt: "Usages:\n- " + path + ":9:19\n- " + path + ":11:20\n", Schema: suggestionSchema}
+	}
+	want, err := fixtureKey(prompt("../../testdata/sample.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"testdata/sample.go", "/src/ai_rename/go/testdata/sample.go", `C:\src\testdata\sample.go`, "sample.go"} {
+		if got, _ := fixtureKey(prompt(path)); got != want {
+			t.Errorf("key with %s = %s, want %s", path, got, want)
+		}
+	}
+	if got, _ := fixtureKey(prompt("../../testdata/order.go")); got == want {
+		t.Error("key ignores the file name")
+	}
+}
@@ -3,8 +3,8 @@ package rename
 import (
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
+	"go/types"
 )
 
 type Selector struct {
@@ -16,54 +16,61 @@ type Selector struct {
 }
 
 func ResolveSelector(
-	filename string,
+	src *Source,
 	selector Selector,
-) (*ast.File, *token.FileSet, *token.File, *ast.Ident, error) {
-
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, 0)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
+) (*ast.Ident, error) {
 
-	tokFile := fset.File(file.Pos())
+	file := src.File
+	tokFile := src.Fset.File(file.Pos())
 	if tokFile == nil {
-		return nil, nil, nil, nil, fmt.Errorf("token file not found")
+		return nil, fmt.Errorf("token file not found")
 	}
 
 	switch selector.Kind {
 	case "funcvar":
-		id, err := resolveFuncVar(file, selector.Func, selector.Var)
-		return file, fset, tokFile, id, err
+		return resolveFuncVar(src, selector.Func, selector.Var)
 
 	case "position":
 		id, err := resolvePosition(tokFile, file, selector.Row, selector.Col)
-		return file, fset, tokFile, id, err
+		if err != nil {
+			// the path of an import has no identifier, but stands for
+			// the import name all the same
+			target := tokFile.LineStart(selector.Row) + token.Pos(selector.Col)
+			for _, spec := range file.Imports {
+				if spec.Path.Pos() <= target && target <= spec.Path.End() {
+					return src.importIdent(spec)
+				}
+			}
+		}
+		return id, err
 
 	default:
-		return nil, nil, nil, nil, fmt.Errorf("unknown selector kind")
+		return nil, fmt.Errorf("unknown selector kind")
 	}
 }
 
+// resolveFuncVar returns the declaring identifier of the first variable
+// named varName in funcName, including its receiver and parameters.
 func resolveFuncVar(
-	file *ast.File,
+	src *Source,
 	funcName, varName string,
 ) (*ast.Ident, error) {
 
-	for _, decl := range file.Decls {
+	for _, decl := range src.File.Decls {
 		fn, ok := decl.(*ast.FuncDecl)
 		if !ok || fn.Name.Name != funcName {
 			continue
 		}
 
 		var found *ast.Ident
-		ast.Inspect(fn.Body, func(n ast.Node) bool {
+		ast.Inspect(fn, func(n ast.Node) bool {
 			id, ok := n.(*ast.Ident)
-			if ok && id.Name == varName && id.Obj != nil {
-				found = id
-				return false
+			if ok && id.Name == varName && found == nil {
+				if _, isVar := src.Info.Defs[id].(*types.Var); isVar {
+					found = id
+				}
 			}
-			return true
+			return found == nil
 		})
 
 		if found != nil {
@@ -86,37 +93,21 @@ func resolvePosition(
 
 	var best *ast.Ident
 
-	// First pass: prefer idents with Obj set (local vars, declarations)
+	// Identifiers never nest, so the first one covering the offset is the
+	// one under the cursor: declarations, uses, selector .Sel idents and
+	// method names alike.
 	ast.Inspect(file, func(n ast.Node) bool {
-		id, ok := n.(*ast.Ident)
-		if !ok || id.Obj == nil {
-			return true
-		}
-		start := tokFile.Offset(id.Pos())
-		end := tokFile.Offset(id.End())
-		if start <= targetOffset && targetOffset <= end {
-			best = id
+		if best != nil {
 			return false
 		}
-		return true
-	})
-
-	if best != nil {
-		return best, nil
-	}
-
-	// Second pass: check selector .Sel idents (struct field usages have no Obj)
-	ast.Inspect(file, func(n ast.Node) bool {
-		sel, ok := n.(*ast.SelectorExpr)
+		id, ok := n.(*ast.Ident)
 		if !ok {
 			return true
 		}
-		id := sel.Sel
 		start := tokFile.Offset(id.Pos())
 		end := tokFile.Offset(id.End())
 		if start <= targetOffset && targetOffset <= end {
 			best = id
-			return false
 		}
 		return true
 	})
@@ -3,6 +3,14 @@ package rename
 type Suggestion struct {
 	Name   string
 	Reason string
+
+	// Mapping holds old→new for every member of a group rename; Name is
+	// then the new name of the selected identifier.
+	Mapping map[string]string
+
+	// Edits applies the suggestion when it spans more than the selected
+	// identifier, e.g. every receiver of a type's methods.
+	Edits []Edit
 }
 
 type Debug struct {
@@ -11,5 +19,7 @@ type Debug struct {
 
 type Result struct {
 	Suggestions []Suggestion
+	Rejected    []Rejection
+	Warnings    []string // what applying a suggestion may break beyond its edits
 	Debug       Debug
 }
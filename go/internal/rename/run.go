@@ -3,132 +3,322 @@ package rename
 import (
 	"fmt"
 	"go/ast"
-	"go/token"
+	"go/types"
 	"strings"
 )
 
-func findEnclosingFuncName(file *ast.File, pos token.Pos) string {
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok || fn.Body == nil {
-			continue
+// Options tunes a Run.
+type Options struct {
+	Load LoadOptions
+
+	// Group renames the whole const (...) block containing the selected
+	// constant, and the named type its members share, as one unit. Each
+	// suggestion then carries a Mapping for every member.
+	Group bool
+}
+
+func Run(filename string, selector Selector, provider Provider, opts Options) (*Result, error) {
+	src, err := Load(filename, opts.Load)
+	if err != nil {
+		return nil, err
+	}
+
+	ident, err := ResolveSelector(src, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Group {
+		ctx, err := BuildGroupContext(src, ident)
+		if err != nil {
+			return nil, err
 		}
-		if fn.Body.Pos() <= pos && pos <= fn.Body.End() {
-			return fn.Name.Name
+		prompt := BuildGroupPrompt(ctx)
+		suggestions, rejected, err := suggestValidGroup(src, ctx, prompt, ident.Name, provider)
+		if err != nil {
+			return nil, err
 		}
+		for i := range suggestions {
+			suggestions[i].Edits = ctx.edits(src, suggestions[i].Mapping)
+		}
+		return &Result{
+			Suggestions: suggestions,
+			Rejected:    rejected,
+			Debug: Debug{
+				Prompt: prompt,
+			},
+		}, nil
 	}
-	return ""
+
+	return suggestFor(src, ident, provider)
 }
 
-// findStructForField checks whether ident is (or refers to) a struct field.
-// It first tries pointer equality (cursor on the declaration), then falls back
-// to name matching for selector .Sel idents (cursor on a usage, Obj == nil).
-func findStructForField(file *ast.File, ident *ast.Ident) (string, bool) {
-	var structName string
-	ast.Inspect(file, func(n ast.Node) bool {
-		typeSpec, ok := n.(*ast.TypeSpec)
-		if !ok {
-			return true
-		}
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
-			return true
-		}
-		for _, field := range structType.Fields.List {
-			for _, name := range field.Names {
-				if name == ident || (ident.Obj == nil && name.Name == ident.Name) {
-					structName = typeSpec.Name.Name
-					return false
-				}
-			}
-		}
-		return true
-	})
-	return structName, structName != ""
+// suggestFor asks provider for valid new names for ident in a loaded
+// package.
+func suggestFor(src *Source, ident *ast.Ident, provider Provider) (*Result, error) {
+	t, err := prepare(src, ident)
+	if err != nil {
+		return nil, err
+	}
+	suggestions, rejected, err := suggestValid(t.prompt, t.validate, withContext(provider, t.context))
+	if err != nil {
+		return nil, err
+	}
+	for i := range suggestions {
+		suggestions[i].Edits = t.edits(suggestions[i].Name)
+	}
+
+	return &Result{
+		Suggestions: suggestions,
+		Rejected:    rejected,
+		Warnings:    t.warnings,
+		Debug: Debug{
+			Prompt: t.prompt,
+		},
+	}, nil
 }
 
-func Run(filename string, selector Selector, provider string) (*Result, error) {
-	var prompt string
-	var name string
+// Rename computes the edits renaming the identifier selector refers to in
+// filename to newName, without asking an LLM. It fails if newName is
+// invalid or would conflict with another identifier, and for methods that
+// satisfy an interface. The result holds a single suggestion carrying the
+// edits.
+func Rename(filename string, selector Selector, newName string, opts Options) (*Result, error) {
+	if opts.Group {
+		return nil, fmt.Errorf("group renames need a name for every member; use Run")
+	}
+
+	src, err := Load(filename, opts.Load)
+	if err != nil {
+		return nil, err
+	}
+	if len(src.TypeErrors) > 0 {
+		return nil, fmt.Errorf("package has type errors, references may be incomplete: %v", src.TypeErrors[0])
+	}
+
+	ident, err := ResolveSelector(src, selector)
+	if err != nil {
+		return nil, err
+	}
+	t, err := prepare(src, ident)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.satisfies) > 0 {
+		return nil, fmt.Errorf("cannot rename %q: it satisfies %s", ident.Name, strings.Join(t.satisfies, ", "))
+	}
+	if err := t.validate(newName); err != nil {
+		return nil, fmt.Errorf("cannot rename %q to %q: %v", ident.Name, newName, err)
+	}
 
-	if selector.Kind == "position" {
-		file, _, _, ident, err := ResolveSelector(filename, selector)
+	return &Result{
+		Suggestions: []Suggestion{{Name: newName, Edits: t.edits(newName)}},
+		Warnings:    t.warnings,
+		Debug: Debug{
+			Prompt: t.prompt,
+		},
+	}, nil
+}
+
+// target describes how to rename the selected identifier: the prompt
+// asking for names and the context it was built from, how to validate a
+// new name and the edits applying it.
+type target struct {
+	prompt   string
+	context  any
+	validate func(newName string) error
+	edits    func(newName string) []Edit
+	warnings []string
+
+	// satisfies lists the interfaces a method helps satisfy. Run only
+	// warns about them; Rename refuses, since the result would not build.
+	satisfies []string
+}
+
+// prepare builds the context of ident and the target renaming it.
+func prepare(src *Source, ident *ast.Ident) (*target, error) {
+	var t target
+	var scope nameScope
+	name := ident.Name
+
+	obj := src.objectOf(ident)
+	switch obj := obj.(type) {
+	case *types.Const:
+		ctx, err := BuildDeclContext(src, ident)
 		if err != nil {
 			return nil, err
 		}
-		name = ident.Name
-
-		if structName, ok := findStructForField(file, ident); ok {
-			ctx, _, _, err := BuildFieldContext(filename, structName, name)
+		t.prompt = BuildDeclPrompt(ctx)
+		t.context = ctx
+		scope = objectScope(src, obj)
+	case *types.Var:
+		if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			ctx, err := BuildDeclContext(src, ident)
 			if err != nil {
 				return nil, err
 			}
-			prompt = BuildFieldPrompt(ctx)
-		} else if ident.Obj != nil && ident.Obj.Kind == ast.Typ {
-			typeSpec, ok := ident.Obj.Decl.(*ast.TypeSpec)
-			if !ok {
-				return nil, fmt.Errorf("type declaration not found for %q", name)
+			t.prompt = BuildDeclPrompt(ctx)
+			t.context = ctx
+			scope = objectScope(src, obj)
+		} else if obj.Embedded() {
+			return nil, fmt.Errorf("%q is an embedded field; rename its type instead", name)
+		} else if obj.IsField() {
+			ctx, err := BuildFieldContext(src, ident)
+			if err != nil {
+				return nil, err
+			}
+			t.prompt = BuildFieldPrompt(ctx)
+			t.context = ctx
+			scope = fieldScope(src, obj)
+			t.validate = func(newName string) error { return validateMember(src, obj, newName, scope) }
+		} else if src.receiverDecl(obj) != nil {
+			ctx, err := BuildReceiverContext(src, ident)
+			if err != nil {
+				return nil, err
 			}
-			typeCtx := buildTypeContext(file, typeSpec)
-			prompt = BuildTypePrompt(typeCtx)
+			t.prompt = BuildReceiverPrompt(ctx)
+			t.context = ctx
+			t.validate = func(newName string) error { return validateReceiver(src, ctx, newName) }
+			t.edits = func(newName string) []Edit { return ctx.edits(src, newName) }
 		} else {
-			funcName := findEnclosingFuncName(file, ident.Pos())
-			ctx, _, _, err := BuildVarContext(filename, funcName, name)
+			ctx, err := BuildVarContext(src, ident)
+			if err != nil {
+				return nil, err
+			}
+			t.prompt = BuildPrompt(ctx)
+			t.context = ctx
+			scope = objectScope(src, obj)
+		}
+	case *types.Func:
+		if obj.Name() == "init" || (obj.Name() == "main" && obj.Pkg().Name() == "main") {
+			return nil, fmt.Errorf("%q cannot be renamed", name)
+		}
+		if isInterfaceMethod(obj) {
+			ctx, err := BuildInterfaceContext(src, ident)
 			if err != nil {
 				return nil, err
 			}
-			prompt = BuildPrompt(ctx)
+			t.prompt = BuildInterfaceMethodPrompt(ctx)
+			t.context = ctx
+			t.validate = func(newName string) error { return validateInterfaceMethod(src, ctx, newName) }
+			t.edits = func(newName string) []Edit { return ctx.edits(src, newName) }
+			t.warnings = ctx.Warnings
+			break
 		}
-	} else {
-		// funcvar path
-		name = selector.Var
-		ctx, _, _, err := BuildVarContext(filename, selector.Func, name)
+		ctx, err := BuildFuncContext(src, ident)
 		if err != nil {
 			return nil, err
 		}
-		prompt = BuildPrompt(ctx)
+		t.prompt = BuildFuncPrompt(ctx)
+		t.context = ctx
+		if obj.Type().(*types.Signature).Recv() != nil {
+			scope = methodScope(obj)
+			t.validate = func(newName string) error { return validateMember(src, obj, newName, scope) }
+			t.satisfies = ctx.Implements
+			for _, iface := range ctx.Implements {
+				t.warnings = append(t.warnings, fmt.Sprintf("%s satisfies %s; renaming it breaks that", name, iface))
+			}
+		} else {
+			scope = objectScope(src, obj)
+		}
+	case *types.TypeName:
+		if _, ok := obj.Type().(*types.TypeParam); ok {
+			ctx, err := BuildTypeParamContext(src, ident)
+			if err != nil {
+				return nil, err
+			}
+			t.prompt = BuildTypeParamPrompt(ctx)
+			t.context = ctx
+			scope = objectScope(src, obj)
+			break
+		}
+		typeSpec := src.typeSpecOf(obj)
+		if typeSpec == nil {
+			return nil, fmt.Errorf("type declaration not found for %q", name)
+		}
+		typeCtx := buildTypeContext(src, typeSpec)
+		t.prompt = BuildTypePrompt(typeCtx)
+		t.context = typeCtx
+		scope = objectScope(src, obj)
+	case *types.PkgName:
+		ctx, err := BuildImportContext(src, ident)
+		if err != nil {
+			return nil, err
+		}
+		t.prompt = BuildImportPrompt(ctx)
+		t.context = ctx
+		t.validate = func(newName string) error { return validateImport(src, ctx, newName) }
+		t.edits = func(newName string) []Edit { return ctx.edits(src, newName) }
+	case nil:
+		return nil, fmt.Errorf("cannot resolve %q", name)
+	default:
+		return nil, fmt.Errorf("renaming %s %q is not supported", src.describe(obj), name)
 	}
 
-	lines, err := CallLLM(prompt, provider)
-	if err != nil {
-		return nil, err
+	if importable(obj) && !src.module {
+		t.warnings = append(t.warnings, fmt.Sprintf("%s is exported; references from other packages are only renamed with -module", name))
 	}
-
-	var suggestions []Suggestion
-	for _, l := range lines {
-		parts := splitOnce(l, " - ")
-		if len(parts) == 2 {
-			suggestions = append(suggestions, Suggestion{
-				Name:   strings.TrimSpace(parts[0]),
-				Reason: strings.TrimSpace(parts[1]),
-			})
-		}
+	if t.validate == nil {
+		t.validate = func(newName string) error { return validateName(name, newName, packageMember(obj), scope) }
 	}
-
-	if len(suggestions) == 0 {
-		return nil, fmt.Errorf("no valid suggestions from LLM")
+	if t.edits == nil {
+		t.edits = func(newName string) []Edit { return src.identEdits(src.renameIdents(obj), newName) }
 	}
+	return &t, nil
+}
 
-	return &Result{
-		Suggestions: suggestions,
-		Debug: Debug{
-			Prompt: prompt,
-		},
-	}, nil
+// importable reports whether other packages can refer to obj: it is an
+// exported package member outside package main.
+func importable(obj types.Object) bool {
+	return obj.Exported() && packageMember(obj) && obj.Pkg().Name() != "main"
 }
 
-func splitOnce(s, sep string) []string {
-	if idx := strings.Index(s, sep); idx >= 0 {
-		return []string{s[:idx], s[idx+len(sep):]}
+// suggestValid calls the LLM and drops suggestions that validate rejects.
+// If every suggestion is rejected it asks once more, telling the model which
+// names were rejected and why.
+func suggestValid(prompt string, validate func(name string) error, provider Provider) ([]Suggestion, []Rejection, error) {
+	const maxRequests = 2
+
+	var rejected []Rejection
+	for attempt := 1; attempt <= maxRequests; attempt++ {
+		p := prompt
+		if len(rejected) > 0 {
+			p += rejectionNote(rejected)
+		}
+		suggestions, err := CallLLM(p, provider)
+		if err != nil {
+			return nil, nil, err
+		}
+		valid, bad := validateSuggestions(suggestions, validate)
+		rejected = append(rejected, bad...)
+		if len(valid) > 0 {
+			return valid, rejected, nil
+		}
+	}
+
+	var reasons []string
+	for _, r := range rejected {
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", r.Name, r.Reason))
 	}
-	return nil
+	return nil, rejected, fmt.Errorf("no valid suggestions from LLM; rejected: %s", strings.Join(reasons, ", "))
 }
 
 // buildTypeContext builds a TypeContext from a resolved TypeSpec.
-func buildTypeContext(file *ast.File, typeSpec *ast.TypeSpec) *TypeContext {
+func buildTypeContext(src *Source, typeSpec *ast.TypeSpec) *TypeContext {
 	ctx := &TypeContext{
-		PackageName: file.Name.Name,
+		PackageName: src.Pkg.Name(),
 		TypeName:    typeSpec.Name.Name,
+		StructDoc:   typeDoc(src.pathTo(typeSpec.Pos()), typeSpec),
+	}
+	if tn, ok := src.Info.Defs[typeSpec.Name].(*types.TypeName); ok {
+		if iface, ok := tn.Type().Underlying().(*types.Interface); ok {
+			ctx.Interface = true
+			ctx.Methods = src.methodSet(tn.Type())
+			for _, t := range src.implementations(iface) {
+				ctx.Implementations = append(ctx.Implementations, src.typeString(t))
+			}
+			return ctx
+		}
 	}
 	structType, ok := typeSpec.Type.(*ast.StructType)
 	if !ok {
@@ -139,19 +329,5 @@ func buildTypeContext(file *ast.File, typeSpec *ast.TypeSpec) *TypeContext {
 			ctx.Fields = append(ctx.Fields, fname.Name)
 		}
 	}
-	// Find the enclosing GenDecl to pick up the doc comment.
-	ast.Inspect(file, func(n ast.Node) bool {
-		genDecl, ok := n.(*ast.GenDecl)
-		if !ok {
-			return true
-		}
-		for _, spec := range genDecl.Specs {
-			if spec == typeSpec && genDecl.Doc != nil {
-				ctx.StructDoc = strings.TrimSpace(genDecl.Doc.Text())
-				return false
-			}
-		}
-		return true
-	})
 	return ctx
 }
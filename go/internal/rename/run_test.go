@@ -0,0 +1,66 @@
+package rename
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fixtures holds the model responses the end-to-end tests replay. They
+// are synthetic: written by hand to exercise the reply parser, fences,
+// prose and rejected names included, rather than captured from a model.
+// Prompts that change need new fixtures: run the tests with
+// AI_RENAME_RECORD set to a provider name to record them.
+const fixtures = "../../testdata/fixtures"
+
+func replayProvider(t *testing.T) *ReplayProvider {
+	t.Helper()
+	t.Setenv("AI_RENAME_FIXTURES", fixtures)
+	p, err := NewReplayProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRunEndToEnd(t *testing.T) {
+	tests := []struct {
+		file     string
+		row, col int
+		want     []string // suggestions
+		rejected []string
+		edits    int // of the first suggestion
+	}{
+		{"sample.go", 9, 18, []string{"nums", "values"}, []string{"len"}, 3},
+		{"sample.go", 10, 1, []string{"sum", "total", "acc"}, nil, 4}, // reply in a code fence
+		{"order.go", 6, 5, []string{"Order", "PurchaseOrder", "CustomerOrder"}, nil, 3},
+		{"order.go", 10, 1, []string{"Amount", "Sum"}, []string{"total"}, 4},
+		{"order.go", 14, 6, []string{"ord", "os", "order"}, nil, 7},                                     // receiver of both methods
+		{"order.go", 14, 22, []string{"Discounted", "DiscountedTotal"}, []string{"applyDiscount"}, 1},   // reply after prose
+		{"order.go", 15, 1, []string{"reduction", "off", "cut"}, []string{"discount", "pct", "len"}, 2}, // all rejected, then asked again
+		{"fibonacci.go", 9, 5, []string{"FibonacciSeries", "Fib"}, []string{"main"}, 2},
+		{"fibonacci.go", 10, 1, []string{"fib", "seq"}, []string{"n"}, 7},
+	}
+	p := replayProvider(t)
+	for _, tt := range tests {
+		result, err := Run(filepath.Join("../../testdata", tt.file), Selector{Kind: "position", Row: tt.row, Col: tt.col}, p, Options{})
+		if err != nil {
+			t.Errorf("%s %d:%d: %v", tt.file, tt.row, tt.col, err)
+			continue
+		}
+		var got, rejected []string
+		for _, s := range result.Suggestions {
+			got = append(got, s.Name)
+		}
+		for _, r := range result.Rejected {
+			rejected = append(rejected, r.Name)
+		}
+		if !slices.Equal(got, tt.want) || !slices.Equal(rejected, tt.rejected) {
+			t.Errorf("%s %d:%d: suggestions = %q, rejected = %q; want %q, %q", tt.file, tt.row, tt.col, got, rejected, tt.want, tt.rejected)
+			continue
+		}
+		if n := len(result.Suggestions[0].Edits); n != tt.edits {
+			t.Errorf("%s %d:%d: %d edits, want %d", tt.file, tt.row, tt.col, n, tt.edits)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// TypeParamContext holds context for a type parameter of a generic
+// function or type to be renamed
+type TypeParamContext struct {
+	PackageName string
+	Name        string
+	Constraint  string // e.g. comparable, ~int | ~float64
+	Owner       string // "func Map" or "type Cache"
+	Doc         string // of the owner
+
+	Siblings  []string // the other type parameters with their constraints, "U any"
+	Signature []string // parameters, results and fields using it, "param s []T"
+	Body      []string // expressions using it, "make([]U, 0, len(s)) (file:line:col)"
+	Methods   []string // methods of a generic type
+}
+
+// BuildTypeParamContext builds context for the type parameter ident
+// refers to.
+func BuildTypeParamContext(src *Source, ident *ast.Ident) (*TypeParamContext, error) {
+	tn, ok := src.Info.ObjectOf(ident).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a type parameter", ident.Name)
+	}
+	tp, ok := tn.Type().(*types.TypeParam)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a type parameter", ident.Name)
+	}
+
+	ctx := &TypeParamContext{
+		PackageName: src.Pkg.Name(),
+		Name:        tn.Name(),
+		Constraint:  src.typeString(tp.Constraint()),
+	}
+
+	var tparams *types.TypeParamList
+	var params, results *ast.FieldList
+	var body ast.Node
+	role := "param"
+	for _, n := range src.pathTo(tn.Pos()) {
+		switch decl := n.(type) {
+		case *ast.FuncDecl:
+			ctx.Owner = "func " + decl.Name.Name
+			ctx.Doc = extractFuncSummary(decl)
+			params, results = decl.Type.Params, decl.Type.Results
+			body = decl.Body
+			if fn, ok := src.Info.Defs[decl.Name].(*types.Func); ok {
+				sig := fn.Type().(*types.Signature)
+				tparams = sig.TypeParams()
+				if decl.Recv != nil {
+					// receivers redeclare the type parameters of their type
+					tparams = sig.RecvTypeParams()
+				}
+			}
+		case *ast.TypeSpec:
+			ctx.Owner = "type " + decl.Name.Name
+			role = "field"
+			ctx.Doc = typeDoc(src.pathTo(decl.Pos()), decl)
+			if st, ok := decl.Type.(*ast.StructType); ok {
+				params = st.Fields
+			}
+			if owner, ok := src.Info.Defs[decl.Name].(*types.TypeName); ok {
+				tparams = owner.Type().(*types.Named).TypeParams()
+				ctx.Methods = src.methodSet(owner.Type())
+			}
+		default:
+			continue
+		}
+		break
+	}
+	if tparams == nil {
+		return nil, fmt.Errorf("declaration of type parameter %q not found", ident.Name)
+	}
+
+	for i := 0; i < tparams.Len(); i++ {
+		if sibling := tparams.At(i); sibling != tp {
+			ctx.Siblings = append(ctx.Siblings, sibling.Obj().Name()+" "+src.typeString(sibling.Constraint()))
+		}
+	}
+
+	ctx.Signature = append(ctx.Signature, src.fieldsUsing(tn, params, role)...)
+	ctx.Signature = append(ctx.Signature, src.fieldsUsing(tn, results, "result")...)
+
+	if body != nil {
+		for _, id := range src.refs(tn, src.fileOf(tn.Pos())) {
+			if body.Pos() <= id.Pos() && id.Pos() < body.End() {
+				ctx.Body = append(ctx.Body, fmt.Sprintf("%s (%s)", src.useString(id), src.position(id.Pos())))
+			}
+		}
+	}
+
+	return ctx, nil
+}
+
+// fieldsUsing describes each field of list whose type mentions tn, e.g.
+// "param s []T" or "result []U" for unnamed fields.
+func (s *Source) fieldsUsing(tn *types.TypeName, list *ast.FieldList, role string) []string {
+	if list == nil {
+		return nil
+	}
+	var out []string
+	for _, field := range list.List {
+		uses := false
+		ast.Inspect(field.Type, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && s.Info.Uses[id] == tn {
+				uses = true
+			}
+			return !uses
+		})
+		if !uses {
+			continue
+		}
+		typ := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			out = append(out, role+" "+typ)
+		}
+		for _, name := range field.Names {
+			out = append(out, role+" "+name.Name+" "+typ)
+		}
+	}
+	return out
+}
+
+// useString renders the outermost expression containing id, or the whole
+// declaration for "var x T".
+func (s *Source) useString(id *ast.Ident) string {
+	var expr ast.Expr = id
+	for _, n := range s.pathTo(id.Pos())[1:] {
+		switch x := n.(type) {
+		case *ast.FuncLit:
+			return types.ExprString(expr)
+		case *ast.ValueSpec:
+			if expr == x.Type {
+				var names []string
+				for _, name := range x.Names {
+					names = append(names, name.Name)
+				}
+				return fmt.Sprintf("var %s %s", strings.Join(names, ", "), types.ExprString(x.Type))
+			}
+			return types.ExprString(expr)
+		case ast.Expr:
+			expr = x
+			continue
+		}
+		break
+	}
+	return types.ExprString(expr)
+}
@@ -0,0 +1,99 @@
+package rename
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const genericSrc = `package p
+
+// Map applies f to every element of s.
+func Map[T, U any](s []T, f func(T) U) []U {
+	out := make([]U, 0, len(s))
+	var zero U
+	_ = zero
+	for _, v := range s {
+		out = append(out, f(v))
+	}
+	return out
+}
+
+// Cache is a fixed set of items.
+type Cache[K comparable, V any] struct {
+	items map[K]V
+	order []K
+}
+
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	v, ok := c.items[k]
+	return v, ok
+}
+`
+
+func TestBuildTypeParamContextFunc(t *testing.T) {
+	src := writeSource(t, genericSrc)
+	ctx, err := BuildTypeParamContext(src, identAt(t, src, "U", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ctx.Owner != "func Map" || ctx.Constraint != "any" || ctx.Doc != "Map applies f to every element of s." {
+		t.Errorf("Owner = %q, Constraint = %q, Doc = %q", ctx.Owner, ctx.Constraint, ctx.Doc)
+	}
+	if want := []string{"T any"}; !reflect.DeepEqual(ctx.Siblings, want) {
+		t.Errorf("Siblings = %q, want %q", ctx.Siblings, want)
+	}
+	if want := []string{"param f func(T) U", "result []U"}; !reflect.DeepEqual(ctx.Signature, want) {
+		t.Errorf("Signature = %q, want %q", ctx.Signature, want)
+	}
+	if len(ctx.Body) != 2 ||
+		!strings.HasPrefix(ctx.Body[0], "make([]U, 0, len(s)) (") ||
+		!strings.HasPrefix(ctx.Body[1], "var zero U (") {
+		t.Errorf("Body = %q", ctx.Body)
+	}
+
+	scope := objectScope(src, src.Info.ObjectOf(identAt(t, src, "U", 0)))
+	if err := validateName("U", "T", false, scope); err == nil || !strings.Contains(err.Error(), `collides with type "T"`) {
+		t.Errorf(`validateName("U", "T") = %v`, err)
+	}
+
+	// type parameters are never exported, so their case may change
+	target, err := prepare(src, identAt(t, src, "T", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := target.validate("elem"); err != nil {
+		t.Errorf(`validate("elem") for T = %v, want nil`, err)
+	}
+}
+
+func TestBuildTypeParamContextType(t *testing.T) {
+	src := writeSource(t, genericSrc)
+
+	ctx, err := BuildTypeParamContext(src, identAt(t, src, "K", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Owner != "type Cache" || ctx.Constraint != "comparable" {
+		t.Errorf("Owner = %q, Constraint = %q", ctx.Owner, ctx.Constraint)
+	}
+	if err := validateName("K", "V", false, objectScope(src, src.Info.ObjectOf(identAt(t, src, "K", 0)))); err == nil {
+		t.Error(`validateName("K", "V") = nil, want collision`)
+	}
+	if want := []string{"field items map[K]V", "field order []K"}; !reflect.DeepEqual(ctx.Signature, want) {
+		t.Errorf("Signature = %q, want %q", ctx.Signature, want)
+	}
+	if want := []string{"Get(k K) (V, bool)"}; !reflect.DeepEqual(ctx.Methods, want) {
+		t.Errorf("Methods = %q, want %q", ctx.Methods, want)
+	}
+
+	// the receiver of Get redeclares K
+	ctx, err = BuildTypeParamContext(src, identAt(t, src, "K", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Owner != "func Get" || !reflect.DeepEqual(ctx.Siblings, []string{"V any"}) {
+		t.Errorf("Owner = %q, Siblings = %q", ctx.Owner, ctx.Siblings)
+	}
+}
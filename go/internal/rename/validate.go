@@ -0,0 +1,352 @@
+package rename
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Rejection records a suggestion that failed validation and why.
+type Rejection struct {
+	Name   string
+	Reason string
+}
+
+// nameScope maps identifiers a new name must not collide with to a short
+// description of what they are, e.g. "parameter" or "package-level func".
+type nameScope map[string]string
+
+func (s nameScope) add(name, what string) {
+	if name == "" || name == "_" {
+		return
+	}
+	if _, ok := s[name]; !ok {
+		s[name] = what
+	}
+}
+
+// validateName reports why name cannot replace old, or nil if it can.
+// Unless member is set, as for locals, parameters and type parameters,
+// which no other package sees, the new name may change case.
+func validateName(old, name string, member bool, scope nameScope) error {
+	switch {
+	case name == old:
+		return fmt.Errorf("same as the current name")
+	case name == "_":
+		return fmt.Errorf("blank identifier")
+	case token.IsKeyword(name):
+		return fmt.Errorf("%q is a Go keyword", name)
+	case !token.IsIdentifier(name):
+		return fmt.Errorf("%q is not a valid Go identifier", name)
+	case types.Universe.Lookup(name) != nil:
+		return fmt.Errorf("shadows predeclared identifier %q", name)
+	case member && token.IsExported(old) && !token.IsExported(name):
+		return fmt.Errorf("would unexport %q", old)
+	case member && !token.IsExported(old) && token.IsExported(name):
+		return fmt.Errorf("would export %q", old)
+	}
+	if what, ok := scope[name]; ok {
+		return fmt.Errorf("collides with %s %q", what, name)
+	}
+	return nil
+}
+
+// validateMember is validateName for the field or method obj, checking
+// the selectors of obj as well as the members it collides with.
+func validateMember(src *Source, obj types.Object, name string, scope nameScope) error {
+	if err := validateName(obj.Name(), name, true, scope); err != nil {
+		return err
+	}
+	return validateSelectors(src, obj, name)
+}
+
+// packageMember reports whether obj is declared at package level or is a
+// field or method, so that the case of its name decides whether other
+// packages see it.
+func packageMember(obj types.Object) bool {
+	if obj.Pkg() == nil {
+		return false
+	}
+	if v, ok := obj.(*types.Var); ok && v.IsField() {
+		return true
+	}
+	if f, ok := obj.(*types.Func); ok && f.Type().(*types.Signature).Recv() != nil {
+		return true
+	}
+	return obj.Parent() == obj.Pkg().Scope()
+}
+
+// validateSuggestions splits suggestions into those validate accepts and
+// rejections explaining the rest.
+func validateSuggestions(suggestions []Suggestion, validate func(name string) error) ([]Suggestion, []Rejection) {
+	var valid []Suggestion
+	var rejected []Rejection
+	for _, s := range suggestions {
+		if err := validate(s.Name); err != nil {
+			rejected = append(rejected, Rejection{Name: s.Name, Reason: err.Error()})
+			continue
+		}
+		valid = append(valid, s)
+	}
+	return valid, rejected
+}
+
+// rejectionNote is appended to a prompt when re-requesting after every
+// suggestion was rejected.
+func rejectionNote(rejected []Rejection) string {
+	var b strings.Builder
+	b.WriteString("\nThe following names were rejected. Do NOT suggest them again:\n")
+	for _, r := range rejected {
+		b.WriteString("- " + r.Name + ": " + r.Reason + "\n")
+	}
+	return b.String()
+}
+
+// objectScope collects the names a new name for obj must not collide with:
+// everything visible where obj is declared, plus names declared in the
+// nested scopes of each reference, which the renamed object would otherwise
+// be captured by.
+func objectScope(src *Source, obj types.Object) nameScope {
+	scope := nameScope{}
+	addScope := func(s *types.Scope) {
+		for _, name := range s.Names() {
+			scope.add(name, src.describe(s.Lookup(name)))
+		}
+	}
+
+	declScope := obj.Parent()
+	if declScope == nil {
+		return scope
+	}
+	for s := declScope; s != nil && s != types.Universe; s = s.Parent() {
+		addScope(s)
+	}
+	// package-level objects are visible in every file, and so are the
+	// imports of each of those files
+	if declScope == src.Pkg.Scope() {
+		for _, f := range src.Files {
+			if fs := src.Info.Scopes[f]; fs != nil {
+				addScope(fs)
+			}
+		}
+	}
+
+	for _, id := range src.refs(obj, src.Files...) {
+		for s := src.Pkg.Scope().Innermost(id.Pos()); s != nil && s != declScope && s != src.Pkg.Scope(); s = s.Parent() {
+			addScope(s)
+		}
+	}
+	return scope
+}
+
+// fieldScope collects the fields and methods of the struct that declares
+// field, since a field may not share a name with either.
+func fieldScope(src *Source, field *types.Var) nameScope {
+	for _, n := range src.pathTo(field.Pos()) {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		if tn, ok := src.Info.Defs[typeSpec.Name].(*types.TypeName); ok {
+			return memberScope(tn.Type())
+		}
+		break
+	}
+
+	// anonymous struct: only sibling fields can collide
+	scope := nameScope{}
+	for _, n := range src.pathTo(field.Pos()) {
+		if st, ok := n.(*ast.StructType); ok {
+			for _, f := range st.Fields.List {
+				for _, name := range f.Names {
+					scope.add(name.Name, "field")
+				}
+			}
+			break
+		}
+	}
+	return scope
+}
+
+// methodScope collects the fields and methods of the receiver type of
+// method.
+func methodScope(method *types.Func) nameScope {
+	recv := method.Type().(*types.Signature).Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	return memberScope(recv)
+}
+
+// memberScope collects the fields and methods of t.
+func memberScope(t types.Type) nameScope {
+	scope := nameScope{}
+	if st, ok := t.Underlying().(*types.Struct); ok {
+		for i := 0; i < st.NumFields(); i++ {
+			scope.add(st.Field(i).Name(), "field")
+		}
+	}
+	if !types.IsInterface(t) {
+		t = types.NewPointer(t)
+	}
+	mset := types.NewMethodSet(t)
+	for i := 0; i < mset.Len(); i++ {
+		scope.add(mset.At(i).Obj().Name(), "method")
+	}
+	return scope
+}
+
+// validateSelectors reports why renaming the field or method obj to name
+// would change what a selector refers to, or nil if it would not. Promoted
+// members make this more than a collision check: a selector reaching obj
+// through embedded fields must not find another member called name first,
+// and a selector of a member called name must not find obj first.
+func validateSelectors(src *Source, obj types.Object, name string) error {
+	var err error
+	for _, f := range src.AllFiles() {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if err != nil {
+				return false
+			}
+			se, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			sel := src.Info.Selections[se]
+			if sel == nil {
+				return true
+			}
+			depth := len(sel.Index()) - 1
+			switch {
+			case origin(sel.Obj()) == obj:
+				other, index, _ := types.LookupFieldOrMethod(sel.Recv(), true, obj.Pkg(), name)
+				if index != nil && len(index)-1 <= depth {
+					err = fmt.Errorf("%s at %s would select %s", types.ExprString(se), src.position(se.Sel.Pos()), memberOrAmbiguous(src, other))
+				}
+			case sel.Obj().Name() == name:
+				if d := memberDepth(sel.Recv(), obj); d >= 0 && d <= depth {
+					err = fmt.Errorf("%s at %s would select the renamed %s", types.ExprString(se), src.position(se.Sel.Pos()), obj.Name())
+				}
+			}
+			return true
+		})
+	}
+	return err
+}
+
+// memberOrAmbiguous describes the member a selector resolves to, which is
+// nil when two members at the same depth make it ambiguous.
+func memberOrAmbiguous(src *Source, obj types.Object) string {
+	if obj == nil {
+		return "an ambiguous name"
+	}
+	return src.describe(obj) + " " + obj.Name()
+}
+
+// memberDepth returns how many embedded fields deep t has the field or
+// method obj, or -1 if it has none.
+func memberDepth(t types.Type, obj types.Object) int {
+	seen := map[*types.Named]bool{}
+	level := []types.Type{t}
+	for depth := 0; len(level) > 0; depth++ {
+		var next []types.Type
+		for _, t := range level {
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			if named, ok := t.(*types.Named); ok {
+				if seen[named] {
+					continue
+				}
+				seen[named] = true
+				for i := 0; i < named.NumMethods(); i++ {
+					if origin(named.Method(i)) == obj {
+						return depth
+					}
+				}
+			}
+			switch u := t.Underlying().(type) {
+			case *types.Struct:
+				for i := 0; i < u.NumFields(); i++ {
+					f := u.Field(i)
+					if origin(f) == obj {
+						return depth
+					}
+					if f.Embedded() {
+						next = append(next, f.Type())
+					}
+				}
+			case *types.Interface:
+				for i := 0; i < u.NumMethods(); i++ {
+					if origin(u.Method(i)) == obj {
+						return depth
+					}
+				}
+			}
+		}
+		level = next
+	}
+	return -1
+}
+
+// origin returns the generic field or method obj was instantiated from,
+// or obj itself.
+func origin(obj types.Object) types.Object {
+	switch o := obj.(type) {
+	case *types.Var:
+		return o.Origin()
+	case *types.Func:
+		return o.Origin()
+	}
+	return obj
+}
+
+// describe names the kind of obj for rejection messages, e.g. "parameter"
+// or "package-level func".
+func (s *Source) describe(obj types.Object) string {
+	var kind string
+	switch o := obj.(type) {
+	case *types.Var:
+		switch {
+		case o.IsField():
+			kind = "field"
+		case s.isParam(o):
+			kind = "parameter"
+		default:
+			kind = "variable"
+		}
+	case *types.Const:
+		kind = "constant"
+	case *types.TypeName:
+		kind = "type"
+	case *types.Func:
+		kind = "func"
+	case *types.PkgName:
+		return "import"
+	case *types.Label:
+		return "label"
+	default:
+		kind = "identifier"
+	}
+	if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+		return "package-level " + kind
+	}
+	return kind
+}
+
+// isParam reports whether v is a receiver, parameter or named result.
+func (s *Source) isParam(v *types.Var) bool {
+	path := s.pathTo(v.Pos())
+	for i, n := range path {
+		if _, ok := n.(*ast.FieldList); ok && i+1 < len(path) {
+			switch path[i+1].(type) {
+			case *ast.FuncType, *ast.FuncDecl:
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
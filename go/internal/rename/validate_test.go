@@ -0,0 +1,126 @@
+package rename
+
+import (
+	"strings"
+	"testing"
+)
+
+// stubProvider returns canned replies in order and records the prompts it saw.
+type stubProvider struct {
+	replies []string
+	prompts []string
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Generate(prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	reply := p.replies[0]
+	if len(p.replies) > 1 {
+		p.replies = p.replies[1:]
+	}
+	return reply, nil
+}
+
+func TestValidateName(t *testing.T) {
+	scope := nameScope{"n": "parameter", "fmt": "import"}
+
+	tests := []struct {
+		old, name string
+		want      string // substring of the error, or "" for valid
+	}{
+		{"num", "fib", ""},
+		{"num", "num", "same as the current name"},
+		{"num", "_", "blank identifier"},
+		{"num", "range", "keyword"},
+		{"num", "fib-seq", "not a valid Go identifier"},
+		{"num", "2fib", "not a valid Go identifier"},
+		{"num", "len", "predeclared"},
+		{"num", "error", "predeclared"},
+		{"num", "Fib", "would export"},
+		{"Total", "total", "would unexport"},
+		{"num", "n", `collides with parameter "n"`},
+		{"num", "fmt", `collides with import "fmt"`},
+	}
+
+	for _, tt := range tests {
+		err := validateName(tt.old, tt.name, true, scope)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateName(%q, %q) = %v, want nil", tt.old, tt.name, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("validateName(%q, %q) = %v, want error containing %q", tt.old, tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestValidateNameLocalMayChangeCase(t *testing.T) {
+	for _, tt := range [][2]string{{"num", "Fib"}, {"Total", "total"}} {
+		if err := validateName(tt[0], tt[1], false, nameScope{}); err != nil {
+			t.Errorf("validateName(%q, %q) of a local = %v, want nil", tt[0], tt[1], err)
+		}
+	}
+}
+
+func TestValidatePromotedSelectors(t *testing.T) {
+	src := writeSource(t, `package p
+
+type Inner struct{ V int }
+
+func (Inner) M() {}
+
+type Outer2 struct {
+	Inner
+	W int
+}
+
+func g(o Outer2) int { o.M(); return o.V + o.Inner.V + o.W }
+`)
+
+	tests := []struct {
+		ident   string
+		newName string
+		want    string // substring of the error, or "" for valid
+	}{
+		{"W", "V", "o.V at"}, // o.V would select the renamed W
+		{"V", "W", "o.V at"}, // o.V would select Outer2.W
+		{"M", "W", "o.M at"}, // o.M() would select Outer2.W
+		{"V", "U", ""},
+		{"W", "U", ""},
+		{"M", "N", ""},
+	}
+	for _, tt := range tests {
+		target, err := prepare(src, identAt(t, src, tt.ident, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = target.validate(tt.newName)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s -> %s: %v, want nil", tt.ident, tt.newName, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%s -> %s: %v, want error containing %q", tt.ident, tt.newName, err, tt.want)
+		}
+	}
+}
+
+func TestRunRejectsAndReRequests(t *testing.T) {
+	p := &stubProvider{replies: []string{
+		`[{"name":"len","reason":"length"},{"name":"n","reason":"count"}]`,
+		`[{"name":"fib","reason":"fibonacci series"}]`,
+	}}
+
+	result, err := Run("../../testdata/fibonacci.go", Selector{Kind: "funcvar", Func: "Fibonacci", Var: "num"}, p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Suggestions) != 1 || result.Suggestions[0].Name != "fib" {
+		t.Errorf("suggestions = %+v", result.Suggestions)
+	}
+	if len(result.Rejected) != 2 {
+		t.Fatalf("rejected = %+v", result.Rejected)
+	}
+	if len(p.prompts) != 2 || !strings.Contains(p.prompts[1], `- n: collides with parameter "n"`) {
+		t.Errorf("second prompt does not explain rejections:\n%s", p.prompts[len(p.prompts)-1])
+	}
+}
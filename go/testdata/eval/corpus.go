@@ -0,0 +1,54 @@
+// Package corpus holds deliberately obfuscated identifiers for the eval
+// subcommand; corpus.json lists each one with the names it originally had.
+package corpus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var e1 = errors.New("config not found")
+
+// Order is a customer order.
+type Order struct {
+	ID    int
+	Items []string
+}
+
+// CustomerStruct is the customer placing an order.
+type CustomerStruct struct {
+	Name   string
+	Orders []*Order
+}
+
+// Summary describes the order in one line.
+func (x *Order) Summary() string {
+	v1 := fmt.Sprintf("order %d: %s", x.ID, strings.Join(x.Items, ", "))
+	return v1
+}
+
+// Sum adds up prices.
+func Sum(a []float64) float64 {
+	t := 0.0
+	for _, p := range a {
+		t += p
+	}
+	return t
+}
+
+// Describe lists the orders of a customer.
+func Describe(c context.Context, cust *CustomerStruct) (string, error) {
+	if err := c.Err(); err != nil {
+		return "", err
+	}
+	var tmp strings.Builder
+	for _, o := range cust.Orders {
+		tmp.WriteString(o.Summary() + "\n")
+	}
+	if tmp.Len() == 0 {
+		return "", e1
+	}
+	return tmp.String(), nil
+}